@@ -0,0 +1,275 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/errors"
+	"github.com/gnanasuryateja/golib/validation"
+)
+
+const (
+	default_consecutive_failures   = 5
+	default_min_requests           = 10
+	default_interval               = 60 * time.Second
+	default_open_timeout           = 30 * time.Second
+	default_half_open_max_requests = 1
+	breaker_metadata_key           = "breaker"
+)
+
+// ErrOpen is returned without calling the datastore while the breaker is open
+var ErrOpen = errors.Unavailable("circuit breaker is open")
+
+type Settings struct {
+	Name                string                             // Name identifies the breaker in the errors and callbacks |
+	ConsecutiveFailures uint32                             // ConsecutiveFailures trips the breaker after so many consecutive failures, 5 by default |
+	FailureRatio        float64                            `validate:"min=0,max=1"` // FailureRatio trips the breaker when failures/requests reaches it (0 disables it) |
+	MinRequests         uint32                             // MinRequests is the number of requests in the Interval before FailureRatio applies, 10 by default |
+	Interval            time.Duration                      `validate:"min=0"` // Interval resets the counts periodically in the closed state, 60s by default |
+	OpenTimeout         time.Duration                      `validate:"min=0"` // OpenTimeout is the time spent open before moving to half-open, 30s by default |
+	HalfOpenMaxRequests uint32                             // HalfOpenMaxRequests is the number of requests let through (and successes needed to close) in half-open, 1 by default |
+	IsFailure           func(err error) bool               // IsFailure classifies the errors counted as failures, IsFailure by default |
+	OnStateChange       func(name string, from, to string) // OnStateChange is called on every state change, it must not call the Breaker |
+	Clock               clock.Clock                        // Clock drives the interval and the open timeout, the real clock by default
+}
+
+// validates the input params
+func (s Settings) validate() error {
+	return validation.Validate(s)
+}
+
+// Counts are the requests counted in the current generation
+type Counts struct {
+	Requests             uint32
+	TotalSuccesses       uint32
+	TotalFailures        uint32
+	ConsecutiveSuccesses uint32
+	ConsecutiveFailures  uint32
+}
+
+// Breaker is a circuit breaker with closed, open and half-open states
+type Breaker struct {
+	settings   Settings
+	lock       sync.Mutex
+	state      string
+	generation uint64
+	counts     Counts
+	expiry     time.Time
+}
+
+// creates a new Breaker in the closed state
+func NewBreaker(settings Settings) (*Breaker, error) {
+
+	// validate the settings
+	err := settings.validate()
+	if err != nil {
+		return nil, err
+	}
+	if settings.ConsecutiveFailures == 0 {
+		settings.ConsecutiveFailures = default_consecutive_failures
+	}
+	if settings.MinRequests == 0 {
+		settings.MinRequests = default_min_requests
+	}
+	if settings.Interval == 0 {
+		settings.Interval = default_interval
+	}
+	if settings.OpenTimeout == 0 {
+		settings.OpenTimeout = default_open_timeout
+	}
+	if settings.HalfOpenMaxRequests == 0 {
+		settings.HalfOpenMaxRequests = default_half_open_max_requests
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = IsFailure
+	}
+	settings.Clock = clock.OrReal(settings.Clock)
+
+	breaker := &Breaker{
+		settings: settings,
+		state:    constants.CIRCUIT_STATE_CLOSED,
+	}
+	breaker.newGeneration(settings.Clock.Now())
+	return breaker, nil
+}
+
+// reports whether the error is a failure of the datastore, caller errors (invalid args, not found, ...) are not
+func IsFailure(err error) bool {
+	return err != nil && !errors.IsCode(err,
+		constants.ERR_CODE_INVALID_ARGUMENT,
+		constants.ERR_CODE_NOT_FOUND,
+		constants.ERR_CODE_ALREADY_EXISTS,
+		constants.ERR_CODE_CONFLICT,
+		constants.ERR_CODE_FAILED_PRECONDITION,
+		constants.ERR_CODE_PERMISSION_DENIED,
+		constants.ERR_CODE_UNAUTHENTICATED,
+		constants.ERR_CODE_CANCELED,
+	)
+}
+
+// returns the current state, one of constants.CIRCUIT_STATE_*
+func (b *Breaker) State() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	state, _ := b.currentState(b.settings.Clock.Now())
+	return state
+}
+
+// returns the counts of the current generation
+func (b *Breaker) Counts() Counts {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.counts
+}
+
+// calls fn when the breaker allows it, otherwise fails fast with ErrOpen
+func (b *Breaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	_, err := ExecuteValue(ctx, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// same as Execute for functions returning a value
+func ExecuteValue[T any](ctx context.Context, b *Breaker, fn func(ctx context.Context) (T, error)) (T, error) {
+	return execute(ctx, b, false, fn)
+}
+
+// calls fn as a probe, probes are always let through in the half-open state |
+// the datastore decorators use it for HealthCheck
+func (b *Breaker) Probe(ctx context.Context, fn func(ctx context.Context) error) error {
+	_, err := execute(ctx, b, true, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+func execute[T any](ctx context.Context, b *Breaker, probe bool, fn func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	generation, err := b.beforeRequest(probe)
+	if err != nil {
+		return zero, err
+	}
+
+	// a panic is counted as a failure before it is propagated
+	defer func() {
+		if r := recover(); r != nil {
+			b.afterRequest(generation, false)
+			panic(r)
+		}
+	}()
+
+	value, err := fn(ctx)
+	b.afterRequest(generation, !b.settings.IsFailure(err))
+	return value, err
+}
+
+func (b *Breaker) beforeRequest(probe bool) (uint64, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	state, generation := b.currentState(b.settings.Clock.Now())
+	switch {
+	case state == constants.CIRCUIT_STATE_OPEN:
+		return generation, ErrOpen.WithMetadata(breaker_metadata_key, b.settings.Name)
+	case state == constants.CIRCUIT_STATE_HALF_OPEN && !probe && b.counts.Requests >= b.settings.HalfOpenMaxRequests:
+		return generation, ErrOpen.WithMessage("circuit breaker is half-open").WithMetadata(breaker_metadata_key, b.settings.Name)
+	}
+	b.counts.Requests++
+	return generation, nil
+}
+
+func (b *Breaker) afterRequest(generation uint64, success bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.settings.Clock.Now()
+	state, currentGeneration := b.currentState(now)
+
+	// ignore the results of the requests started in an older generation
+	if generation != currentGeneration {
+		return
+	}
+	if success {
+		b.onSuccess(state, now)
+	} else {
+		b.onFailure(state, now)
+	}
+}
+
+func (b *Breaker) onSuccess(state string, now time.Time) {
+	b.counts.TotalSuccesses++
+	b.counts.ConsecutiveSuccesses++
+	b.counts.ConsecutiveFailures = 0
+	if state == constants.CIRCUIT_STATE_HALF_OPEN && b.counts.ConsecutiveSuccesses >= b.settings.HalfOpenMaxRequests {
+		b.setState(constants.CIRCUIT_STATE_CLOSED, now)
+	}
+}
+
+func (b *Breaker) onFailure(state string, now time.Time) {
+	b.counts.TotalFailures++
+	b.counts.ConsecutiveFailures++
+	b.counts.ConsecutiveSuccesses = 0
+	switch state {
+	case constants.CIRCUIT_STATE_CLOSED:
+		if b.readyToTrip() {
+			b.setState(constants.CIRCUIT_STATE_OPEN, now)
+		}
+	case constants.CIRCUIT_STATE_HALF_OPEN:
+		b.setState(constants.CIRCUIT_STATE_OPEN, now)
+	}
+}
+
+// reports whether the counts meet one of the trip conditions
+func (b *Breaker) readyToTrip() bool {
+	if b.counts.ConsecutiveFailures >= b.settings.ConsecutiveFailures {
+		return true
+	}
+	if b.settings.FailureRatio > 0 && b.counts.Requests >= b.settings.MinRequests {
+		return float64(b.counts.TotalFailures)/float64(b.counts.Requests) >= b.settings.FailureRatio
+	}
+	return false
+}
+
+// returns the state after applying the expiries (interval in closed, timeout in open)
+func (b *Breaker) currentState(now time.Time) (string, uint64) {
+	switch b.state {
+	case constants.CIRCUIT_STATE_CLOSED:
+		if !b.expiry.IsZero() && b.expiry.Before(now) {
+			b.newGeneration(now)
+		}
+	case constants.CIRCUIT_STATE_OPEN:
+		if b.expiry.Before(now) {
+			b.setState(constants.CIRCUIT_STATE_HALF_OPEN, now)
+		}
+	}
+	return b.state, b.generation
+}
+
+func (b *Breaker) setState(state string, now time.Time) {
+	if b.state == state {
+		return
+	}
+	previous := b.state
+	b.state = state
+	b.newGeneration(now)
+	if b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(b.settings.Name, previous, state)
+	}
+}
+
+func (b *Breaker) newGeneration(now time.Time) {
+	b.generation++
+	b.counts = Counts{}
+	switch b.state {
+	case constants.CIRCUIT_STATE_CLOSED:
+		b.expiry = now.Add(b.settings.Interval)
+	case constants.CIRCUIT_STATE_OPEN:
+		b.expiry = now.Add(b.settings.OpenTimeout)
+	default:
+		b.expiry = time.Time{}
+	}
+}
@@ -0,0 +1,34 @@
+package circuitbreaker
+
+import (
+	"context"
+
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/datastore/cache"
+	"github.com/gnanasuryateja/golib/datastore/database"
+	"github.com/gnanasuryateja/golib/datastore/decorator"
+	messagingqueue "github.com/gnanasuryateja/golib/datastore/messaging_queue"
+)
+
+// wraps the cache so that every operation goes through the breaker
+func NewCache(c cache.Cache, breaker *Breaker) cache.Cache {
+	return decorator.NewCache(c, breaker.intercept)
+}
+
+// wraps the database so that every operation goes through the breaker
+func NewDatabase(db database.Database, breaker *Breaker) database.Database {
+	return decorator.NewDatabase(db, breaker.intercept)
+}
+
+// wraps the message queue so that every operation goes through the breaker
+func NewMessageQueue(messageQueue messagingqueue.MessageQueue, breaker *Breaker) messagingqueue.MessageQueue {
+	return decorator.NewMessageQueue(messageQueue, breaker.intercept)
+}
+
+// runs the operation through the breaker, health checks are let through as probes
+func (b *Breaker) intercept(ctx context.Context, operation decorator.Operation, fn func(ctx context.Context) error) error {
+	if operation.Kind == constants.OPERATION_KIND_HEALTH_CHECK {
+		return b.Probe(ctx, fn)
+	}
+	return b.Execute(ctx, fn)
+}
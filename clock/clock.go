@@ -0,0 +1,105 @@
+package clock
+
+import (
+	"time"
+)
+
+// Clock abstracts the time so that the time dependent code can be tested with the Fake clock
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Until(t time.Time) time.Duration
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is the Clock counterpart of time.Timer
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the Clock counterpart of time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+type realClock struct{}
+
+// returns the Clock backed by the time package
+func New() Clock {
+	return realClock{}
+}
+
+// returns the passed clock, or the real clock when it is nil
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return New()
+	}
+	return c
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (realClock) Until(t time.Time) time.Duration {
+	return time.Until(t)
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{timer: time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{ticker: time.NewTicker(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (rt realTimer) C() <-chan time.Time {
+	return rt.timer.C
+}
+
+func (rt realTimer) Stop() bool {
+	return rt.timer.Stop()
+}
+
+func (rt realTimer) Reset(d time.Duration) bool {
+	return rt.timer.Reset(d)
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (rt realTicker) C() <-chan time.Time {
+	return rt.ticker.C
+}
+
+func (rt realTicker) Stop() {
+	rt.ticker.Stop()
+}
+
+func (rt realTicker) Reset(d time.Duration) {
+	rt.ticker.Reset(d)
+}
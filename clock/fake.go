@@ -0,0 +1,232 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock which only moves when Advance or Set is called |
+// timers, tickers, After and Sleep fire when the fake time reaches them
+type Fake struct {
+	lock    sync.Mutex
+	now     time.Time
+	waiters []*waiter
+	changed chan struct{}
+}
+
+type waiter struct {
+	when   time.Time
+	period time.Duration
+	ch     chan time.Time
+	active bool
+}
+
+// creates a new Fake clock frozen at start
+func NewFake(start time.Time) *Fake {
+	return &Fake{
+		now:     start,
+		changed: make(chan struct{}),
+	}
+}
+
+func (f *Fake) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+func (f *Fake) Until(t time.Time) time.Duration {
+	return t.Sub(f.Now())
+}
+
+// blocks until the fake time is advanced by d
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	w := &waiter{
+		ch: make(chan time.Time, 1),
+	}
+	f.schedule(w, d, 0)
+	return &fakeTimer{fake: f, waiter: w}
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	w := &waiter{
+		ch: make(chan time.Time, 1),
+	}
+	f.schedule(w, d, d)
+	return &fakeTicker{fake: f, waiter: w}
+}
+
+// moves the fake time forward by d and fires the timers and tickers which are due
+func (f *Fake) Advance(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.setLocked(f.now.Add(d))
+}
+
+// moves the fake time to t and fires the timers and tickers which are due
+func (f *Fake) Set(t time.Time) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.setLocked(t)
+}
+
+// returns the number of the active timers, tickers and sleepers
+func (f *Fake) Waiters() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.waiters)
+}
+
+// blocks until at least n timers, tickers or sleepers are active |
+// it lets a test wait for a goroutine to start sleeping before advancing the time
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.lock.Lock()
+		count := len(f.waiters)
+		changed := f.changed
+		f.lock.Unlock()
+		if count >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+func (f *Fake) setLocked(t time.Time) {
+	f.now = t
+
+	// fire the waiters in the order they are due
+	sort.SliceStable(f.waiters, func(i, j int) bool {
+		return f.waiters[i].when.Before(f.waiters[j].when)
+	})
+	var remaining []*waiter
+	for _, w := range f.waiters {
+		if w.when.After(f.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+
+		// like the time package, a tick is dropped when the previous one was not received
+		select {
+		case w.ch <- f.now:
+		default:
+		}
+		if w.period > 0 {
+			for !w.when.After(f.now) {
+				w.when = w.when.Add(w.period)
+			}
+			remaining = append(remaining, w)
+			continue
+		}
+		w.active = false
+	}
+	f.waiters = remaining
+	f.notify()
+}
+
+// adds (or moves) the waiter to fire after d
+func (f *Fake) schedule(w *waiter, d time.Duration, period time.Duration) {
+	f.unschedule(w)
+	w.when = f.now.Add(d)
+	w.period = period
+	w.active = true
+	if d <= 0 && period == 0 {
+		select {
+		case w.ch <- f.now:
+		default:
+		}
+		w.active = false
+		return
+	}
+	f.waiters = append(f.waiters, w)
+	f.notify()
+}
+
+// removes the waiter, reports whether it was active
+func (f *Fake) unschedule(w *waiter) bool {
+	wasActive := w.active
+	for i, existing := range f.waiters {
+		if existing == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			break
+		}
+	}
+	w.active = false
+	return wasActive
+}
+
+// wakes up the BlockUntil callers
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+type fakeTimer struct {
+	fake   *Fake
+	waiter *waiter
+}
+
+func (ft *fakeTimer) C() <-chan time.Time {
+	return ft.waiter.ch
+}
+
+func (ft *fakeTimer) Stop() bool {
+	ft.fake.lock.Lock()
+	defer ft.fake.lock.Unlock()
+	stopped := ft.fake.unschedule(ft.waiter)
+	ft.fake.notify()
+	return stopped
+}
+
+func (ft *fakeTimer) Reset(d time.Duration) bool {
+	ft.fake.lock.Lock()
+	defer ft.fake.lock.Unlock()
+	wasActive := ft.waiter.active
+	ft.fake.schedule(ft.waiter, d, 0)
+	return wasActive
+}
+
+type fakeTicker struct {
+	fake   *Fake
+	waiter *waiter
+}
+
+func (ft *fakeTicker) C() <-chan time.Time {
+	return ft.waiter.ch
+}
+
+func (ft *fakeTicker) Stop() {
+	ft.fake.lock.Lock()
+	defer ft.fake.lock.Unlock()
+	ft.fake.unschedule(ft.waiter)
+	ft.fake.notify()
+}
+
+func (ft *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	ft.fake.lock.Lock()
+	defer ft.fake.lock.Unlock()
+	ft.fake.schedule(ft.waiter, d, d)
+}
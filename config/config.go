@@ -0,0 +1,282 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/validation"
+)
+
+const (
+	config_tag       = "config"
+	env_tag          = "env"
+	default_tag      = "default"
+	precedence_tag   = "precedence"
+	required_option  = "required"
+	profiles_key     = "profiles"
+	skip_field_name  = "-"
+	env_name_divider = "_"
+)
+
+// default precedence of the sources, from the lowest to the highest
+var defaultPrecedence = []string{
+	constants.CONFIG_SOURCE_DEFAULT,
+	constants.CONFIG_SOURCE_FILE,
+	constants.CONFIG_SOURCE_ENV,
+}
+
+type LoaderConfig struct {
+	EnvPrefix  string                          // EnvPrefix is prepended to the env var names, REDIS gives REDIS_ADDR for the field addr |
+	Files      []string                        // Files are the json/yaml files to read, later files override the earlier ones |
+	Env        string                          // Env selects the profile (dev/staging/prod) from the profiles section of the files |
+	Precedence []string                        `validate:"dive,oneof=default file env"` // Precedence lists the sources from the lowest to the highest, default < file < env when empty |
+	LookupEnv  func(key string) (string, bool) // LookupEnv looks up the env vars, os.LookupEnv when nil
+}
+
+// validates the input params
+func (lc LoaderConfig) validate() error {
+	return validation.Validate(lc)
+}
+
+// FieldError describes a missing or invalid field
+type FieldError struct {
+	Field   string // Field is the path of the field (e.g. Tls.CA) |
+	Source  string // Source is the source of the invalid value, empty for missing fields |
+	Message string // Message describes the problem
+}
+
+func (fe FieldError) Error() string {
+	if fe.Source == "" {
+		return fe.Field + ": " + fe.Message
+	}
+	return fe.Field + " (" + fe.Source + "): " + fe.Message
+}
+
+// Errors has all the problems found while loading the config
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fieldError := range e {
+		msgs = append(msgs, fieldError.Error())
+	}
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+// fills the target (a pointer to a struct) from the defaults, files and env vars |
+// fields are configured with the tags config:"name,required", env:"VAR", default:"value" and precedence:"file,env" |
+// all the missing and invalid fields are reported at once as Errors
+func Load(target any, loaderConfig LoaderConfig) error {
+
+	// validate the loaderConfig
+	err := loaderConfig.validate()
+	if err != nil {
+		return err
+	}
+
+	// the target must be a pointer to a struct
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Pointer || targetValue.IsNil() || targetValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("target must be a non nil pointer to a struct")
+	}
+
+	if loaderConfig.LookupEnv == nil {
+		loaderConfig.LookupEnv = os.LookupEnv
+	}
+	if len(loaderConfig.Precedence) == 0 {
+		loaderConfig.Precedence = defaultPrecedence
+	}
+
+	// read the files
+	fileValues := map[string]any{}
+	for _, file := range loaderConfig.Files {
+		values, err := readFile(file, loaderConfig.Env)
+		if err != nil {
+			return err
+		}
+		merge(fileValues, values)
+	}
+
+	// load the fields
+	l := loader{
+		loaderConfig: loaderConfig,
+	}
+	l.loadStruct(targetValue.Elem(), "", loaderConfig.EnvPrefix, fileValues)
+	if len(l.errs) > 0 {
+		return l.errs
+	}
+	return nil
+}
+
+type loader struct {
+	loaderConfig LoaderConfig
+	errs         Errors
+}
+
+// loads all the exported fields of the struct
+func (l *loader) loadStruct(structValue reflect.Value, path string, envPrefix string, fileValues map[string]any) {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		// get the name and options from the config tag
+		name, options, _ := strings.Cut(field.Tag.Get(config_tag), ",")
+		if name == skip_field_name {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+		envName := strings.ToUpper(name)
+		if envPrefix != "" {
+			envName = envPrefix + env_name_divider + envName
+		}
+
+		// nested structs are loaded recursively
+		fieldValue := structValue.Field(i)
+		if fieldValue.Kind() == reflect.Struct && !isScalar(fieldValue.Type()) {
+			nested, _ := lookup(fileValues, name).(map[string]any)
+			l.loadStruct(fieldValue, fieldPath, envName, nested)
+			continue
+		}
+
+		// get the values from every source
+		values := map[string]any{}
+		if def, ok := field.Tag.Lookup(default_tag); ok {
+			values[constants.CONFIG_SOURCE_DEFAULT] = def
+		}
+		if fileValue := lookup(fileValues, name); fileValue != nil {
+			values[constants.CONFIG_SOURCE_FILE] = fileValue
+		}
+		if envTag := field.Tag.Get(env_tag); envTag != "" {
+			envName = envTag
+		}
+		if envValue, ok := l.loaderConfig.LookupEnv(envName); ok {
+			values[constants.CONFIG_SOURCE_ENV] = envValue
+		}
+
+		// apply the values from the lowest to the highest precedence
+		precedence := l.loaderConfig.Precedence
+		if precedenceTag := field.Tag.Get(precedence_tag); precedenceTag != "" {
+			precedence = strings.Split(precedenceTag, ",")
+		}
+		for _, source := range precedence {
+			source = strings.TrimSpace(source)
+			if !isSource(source) {
+				l.errs = append(l.errs, FieldError{Field: fieldPath, Message: "invalid precedence source " + source})
+				continue
+			}
+			value, ok := values[source]
+			if !ok {
+				continue
+			}
+			err := setValue(fieldValue, value)
+			if err != nil {
+				l.errs = append(l.errs, FieldError{Field: fieldPath, Source: source, Message: err.Error()})
+			}
+		}
+
+		// check the required fields
+		if hasOption(options, required_option) && isMissing(fieldValue) {
+			l.errs = append(l.errs, FieldError{Field: fieldPath, Message: "required value is missing (env " + envName + ")"})
+		}
+	}
+}
+
+// reads the json/yaml file and overlays the profile of the env
+func readFile(file string, env string) (map[string]any, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the config file %s: %w", file, err)
+	}
+	values := map[string]any{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		err = json.Unmarshal(content, &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &values)
+	default:
+		return nil, fmt.Errorf("unsupported config file %s... only json and yaml are supported", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the config file %s: %w", file, err)
+	}
+
+	// overlay the profile of the env
+	values = normalize(values)
+	profiles, _ := lookup(values, profiles_key).(map[string]any)
+	delete(values, profiles_key)
+	if env != "" {
+		if profile, ok := lookup(profiles, env).(map[string]any); ok {
+			merge(values, profile)
+		}
+	}
+	return values, nil
+}
+
+// merges src into dst, nested maps are merged recursively
+func merge(dst map[string]any, src map[string]any) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			merge(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// looks up the key case insensitively, the keys of the file values are lower cased on read
+func lookup(values map[string]any, key string) any {
+	return values[strings.ToLower(key)]
+}
+
+// lower cases the keys recursively
+func normalize(values map[string]any) map[string]any {
+	normalized := make(map[string]any, len(values))
+	for key, value := range values {
+		if nested, ok := value.(map[string]any); ok {
+			value = normalize(nested)
+		}
+		normalized[strings.ToLower(key)] = value
+	}
+	return normalized
+}
+
+// reports whether the source is a known config source
+func isSource(source string) bool {
+	return source == constants.CONFIG_SOURCE_DEFAULT || source == constants.CONFIG_SOURCE_FILE || source == constants.CONFIG_SOURCE_ENV
+}
+
+// reports whether the comma separated options contain the option
+func hasOption(options string, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// reports whether the field has no value
+func isMissing(fieldValue reflect.Value) bool {
+	if fieldValue.Kind() == reflect.Pointer {
+		return fieldValue.IsNil()
+	}
+	return fieldValue.IsZero()
+}
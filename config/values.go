@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gnanasuryateja/golib/utils"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// reports whether the type is set from a single value rather than loaded field by field
+func isScalar(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// sets the raw value (a string from env/default or a decoded file value) into the field
+func setValue(fieldValue reflect.Value, raw any) error {
+
+	// allocate the pointers
+	if fieldValue.Kind() == reflect.Pointer {
+		elem := reflect.New(fieldValue.Type().Elem())
+		err := setValue(elem.Elem(), raw)
+		if err != nil {
+			return err
+		}
+		fieldValue.Set(elem)
+		return nil
+	}
+
+	// the types which can unmarshal themselves
+	if isScalar(fieldValue.Type()) {
+		str, err := toString(raw)
+		if err != nil {
+			return err
+		}
+		return fieldValue.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(str))
+	}
+
+	// slices come as lists from the files and as comma separated strings from env/default
+	if fieldValue.Kind() == reflect.Slice {
+		var items []any
+		switch rawValue := raw.(type) {
+		case []any:
+			items = rawValue
+		default:
+			str, err := toString(raw)
+			if err != nil {
+				return err
+			}
+			for _, item := range strings.Split(str, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		}
+		slice := reflect.MakeSlice(fieldValue.Type(), len(items), len(items))
+		for i, item := range items {
+			err := setValue(slice.Index(i), item)
+			if err != nil {
+				return err
+			}
+		}
+		fieldValue.Set(slice)
+		return nil
+	}
+
+	str, err := toString(raw)
+	if err != nil {
+		return err
+	}
+
+	// durations are int64 kinds but are parsed as durations
+	if fieldValue.Type() == durationType {
+		duration, err := utils.ParseDuration(str)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetInt(int64(duration))
+		return nil
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.String:
+		fieldValue.SetString(str)
+	case reflect.Bool:
+		b, err := utils.ParseBool(str)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num, err := utils.ParseInt64(str)
+		if err != nil {
+			return err
+		}
+		if fieldValue.OverflowInt(num) {
+			return fmt.Errorf("%d overflows %s", num, fieldValue.Type())
+		}
+		fieldValue.SetInt(num)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		num, err := utils.ParseUint64(str)
+		if err != nil {
+			return err
+		}
+		if fieldValue.OverflowUint(num) {
+			return fmt.Errorf("%d overflows %s", num, fieldValue.Type())
+		}
+		fieldValue.SetUint(num)
+	case reflect.Float32, reflect.Float64:
+		num, err := utils.ParseFloat64(str)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetFloat(num)
+	default:
+		return fmt.Errorf("unsupported field type %s", fieldValue.Type())
+	}
+	return nil
+}
+
+// converts the decoded scalar value into a string
+func toString(raw any) (string, error) {
+	switch value := raw.(type) {
+	case string:
+		return value, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case bool, int, int64, uint64:
+		return fmt.Sprint(value), nil
+	case map[string]any, []any:
+		return "", fmt.Errorf("expected a single value but got %T", raw)
+	}
+	return fmt.Sprint(raw), nil
+}
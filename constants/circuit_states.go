@@ -0,0 +1,7 @@
+package constants
+
+const (
+	CIRCUIT_STATE_CLOSED    = "closed"
+	CIRCUIT_STATE_OPEN      = "open"
+	CIRCUIT_STATE_HALF_OPEN = "half-open"
+)
@@ -0,0 +1,7 @@
+package constants
+
+const (
+	CONFIG_SOURCE_DEFAULT = "default"
+	CONFIG_SOURCE_FILE    = "file"
+	CONFIG_SOURCE_ENV     = "env"
+)
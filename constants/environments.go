@@ -0,0 +1,7 @@
+package constants
+
+const (
+	ENV_DEV     = "dev"
+	ENV_STAGING = "staging"
+	ENV_PROD    = "prod"
+)
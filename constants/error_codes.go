@@ -0,0 +1,18 @@
+package constants
+
+const (
+	ERR_CODE_UNKNOWN             = "UNKNOWN"
+	ERR_CODE_INTERNAL            = "INTERNAL"
+	ERR_CODE_INVALID_ARGUMENT    = "INVALID_ARGUMENT"
+	ERR_CODE_NOT_FOUND           = "NOT_FOUND"
+	ERR_CODE_ALREADY_EXISTS      = "ALREADY_EXISTS"
+	ERR_CODE_CONFLICT            = "CONFLICT"
+	ERR_CODE_PERMISSION_DENIED   = "PERMISSION_DENIED"
+	ERR_CODE_UNAUTHENTICATED     = "UNAUTHENTICATED"
+	ERR_CODE_RESOURCE_EXHAUSTED  = "RESOURCE_EXHAUSTED"
+	ERR_CODE_FAILED_PRECONDITION = "FAILED_PRECONDITION"
+	ERR_CODE_UNAVAILABLE         = "UNAVAILABLE"
+	ERR_CODE_DEADLINE_EXCEEDED   = "DEADLINE_EXCEEDED"
+	ERR_CODE_CANCELED            = "CANCELED"
+	ERR_CODE_UNIMPLEMENTED       = "UNIMPLEMENTED"
+)
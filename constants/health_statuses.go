@@ -0,0 +1,7 @@
+package constants
+
+const (
+	HEALTH_STATUS_UP       = "up"
+	HEALTH_STATUS_DOWN     = "down"
+	HEALTH_STATUS_DEGRADED = "degraded"
+)
@@ -0,0 +1,7 @@
+package constants
+
+const (
+	OPERATION_KIND_READ         = "read"
+	OPERATION_KIND_WRITE        = "write"
+	OPERATION_KIND_HEALTH_CHECK = "health_check"
+)
@@ -3,4 +3,8 @@ package constants
 const (
 	USERNAME = "<username>"
 	PASSWORD = "<password>"
+	HOST     = "<host>"
+	HOSTS    = "<hosts>"
+	PORT     = "<port>"
+	DATABASE = "<database>"
 )
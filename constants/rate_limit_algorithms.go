@@ -0,0 +1,7 @@
+package constants
+
+const (
+	RATE_LIMIT_ALGORITHM_TOKEN_BUCKET       = "token_bucket"
+	RATE_LIMIT_ALGORITHM_SLIDING_WINDOW_LOG = "sliding_window_log"
+	RATE_LIMIT_ALGORITHM_GCRA               = "gcra"
+)
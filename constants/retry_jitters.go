@@ -0,0 +1,7 @@
+package constants
+
+const (
+	RETRY_JITTER_NONE         = "none"
+	RETRY_JITTER_FULL         = "full"
+	RETRY_JITTER_DECORRELATED = "decorrelated"
+)
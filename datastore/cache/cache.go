@@ -1,11 +1,55 @@
 package cache
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/gnanasuryateja/golib/datastore"
+)
+
+// NoExpiration is returned by TTL for the keys which never expire
+const NoExpiration time.Duration = -1
 
 type Cache interface {
+	Close(ctx context.Context) error
 	HealthCheck(ctx context.Context) error
 	AddData(ctx context.Context, args ...any) (string, error)
 	GetData(ctx context.Context, args ...any) (any, error)
 	GetKeys(ctx context.Context, pattern string) ([]string, error)
 	DeleteData(ctx context.Context, args ...any) (any, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) (bool, error)
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Persist(ctx context.Context, key string) (bool, error)
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// Options are the options of AddData
+type Options struct {
+	Expiration time.Duration // Expiration is the time to live of the key, the key never expires when zero
+}
+
+// Option is passed to AddData after the key and the value
+type Option func(options *Options)
+
+// sets the time to live of the added key, e.g. AddData(ctx, key, value, cache.WithExpiration(time.Minute))
+func WithExpiration(expiration time.Duration) Option {
+	return func(options *Options) {
+		options.Expiration = expiration
+	}
+}
+
+// applies the Options passed to AddData, anything else than an Option is an invalid argument
+func ParseOptions(args ...any) (Options, error) {
+	var options Options
+	for _, arg := range args {
+		option, ok := arg.(Option)
+		if !ok || option == nil {
+			return options, datastore.ErrInvalidArgs.WithMessage("invalid option is passed (not a cache.Option)")
+		}
+		option(&options)
+	}
+	if options.Expiration < 0 {
+		return options, datastore.ErrInvalidArgs.WithMessage("expiration cannot be negative")
+	}
+	return options, nil
 }
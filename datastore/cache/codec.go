@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Codec encodes the values of a TypedCache
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	IsJSON() bool // IsJSON reports whether Marshal returns json, which is stored as a json document instead of a base64 json string
+}
+
+var (
+	JSONCodec Codec = jsonCodec{} // JSONCodec stores the values as json documents, readable with JSON.GET by the other clients |
+	GobCodec  Codec = gobCodec{}  // GobCodec stores the values as gob, which keeps the unexported types of interface fields
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) IsJSON() bool {
+	return true
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (gobCodec) IsJSON() bool {
+	return false
+}
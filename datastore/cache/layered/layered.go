@@ -0,0 +1,493 @@
+package layered
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/datastore"
+	cache "github.com/gnanasuryateja/golib/datastore/cache"
+	"github.com/gnanasuryateja/golib/datastore/cache/memory"
+	redisstore "github.com/gnanasuryateja/golib/datastore/cache/redis"
+	"github.com/gnanasuryateja/golib/errors"
+	"github.com/gnanasuryateja/golib/idgen"
+	"github.com/gnanasuryateja/golib/validation"
+)
+
+const (
+	layered_default_l1_ttl      = time.Minute
+	layered_default_l1_entries  = 10000
+	layered_default_channel     = "cache:invalidate"
+	layered_resubscribe_backoff = time.Second
+	layered_generation_shards   = 256
+)
+
+type LayeredStoreConfig struct {
+	L1      cache.Cache   `validate:"-"`        // L1 is the in-process layer, a memory store of 10000 entries by default |
+	L2      cache.Cache   `validate:"required"` // L2 is the redis store shared by the instances, its client carries the invalidations |
+	L1TTL   time.Duration `validate:"min=0"`    // L1TTL caps the time a key stays in L1, it bounds the staleness when an invalidation is missed, 1m by default |
+	Channel string        // Channel is the pub/sub channel of the invalidations, cache:invalidate by default |
+	Clock   clock.Clock   `validate:"-"` // Clock drives the resubscribe backoff and the default L1, the real clock by default
+}
+
+// validates the input params
+func (lsc LayeredStoreConfig) validate() error {
+	err := validation.Validate(lsc)
+	if err != nil {
+		return datastore.Wrap(datastore.ErrInvalidArgs, "invalid LayeredStoreConfig", err)
+	}
+	return nil
+}
+
+// Stats are the hits and misses of every layer
+type Stats struct {
+	L1Hits        uint64 `json:"l1_hits"`
+	L1Misses      uint64 `json:"l1_misses"`
+	L2Hits        uint64 `json:"l2_hits"`
+	L2Misses      uint64 `json:"l2_misses"`
+	Invalidations uint64 `json:"invalidations"` // Invalidations is the number of keys evicted from L1 by the other instances
+}
+
+// invalidation is published on the channel when an instance changes keys
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+// generation counts the invalidations of the keys of a shard, an L1 fill is dropped when it changed since the L2 read
+type generation struct {
+	lock  sync.Mutex
+	value uint64
+}
+
+type layeredStore struct {
+	l1            cache.Cache
+	l2            cache.Cache
+	client        *redis.Client
+	pubsub        *redis.PubSub
+	l1TTL         time.Duration
+	channel       string
+	origin        string
+	clock         clock.Clock
+	generations   [layered_generation_shards]generation
+	l1Hits        atomic.Uint64
+	l1Misses      atomic.Uint64
+	l2Hits        atomic.Uint64
+	l2Misses      atomic.Uint64
+	invalidations atomic.Uint64
+	closed        atomic.Bool
+	cancel        context.CancelFunc
+	done          sync.WaitGroup
+}
+
+// creates a new layeredStore client keeping the hot keys of the redis store (L2) in process (L1) |
+// the changes are published on the redis pub/sub channel so that every instance evicts them from its L1
+func NewLayeredStoreClient(ctx context.Context, layeredStoreConfig LayeredStoreConfig) (cache.Cache, error) {
+
+	// validate the layeredStoreConfig
+	err := layeredStoreConfig.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	// get the redis client of L2 for the pub/sub
+	client, err := redisstore.GetRedisClient(layeredStoreConfig.L2)
+	if err != nil {
+		return nil, err
+	}
+
+	// set the defaults
+	layeredStoreConfig.Clock = clock.OrReal(layeredStoreConfig.Clock)
+	if layeredStoreConfig.L1 == nil {
+		layeredStoreConfig.L1, err = memory.NewMemoryStoreClient(ctx, memory.MemoryStoreConfig{
+			MaxEntries: layered_default_l1_entries,
+			Clock:      layeredStoreConfig.Clock,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if layeredStoreConfig.L1TTL == 0 {
+		layeredStoreConfig.L1TTL = layered_default_l1_ttl
+	}
+	if layeredStoreConfig.Channel == "" {
+		layeredStoreConfig.Channel = layered_default_channel
+	}
+
+	ls := &layeredStore{
+		l1:      layeredStoreConfig.L1,
+		l2:      layeredStoreConfig.L2,
+		client:  client,
+		l1TTL:   layeredStoreConfig.L1TTL,
+		channel: layeredStoreConfig.Channel,
+		origin:  idgen.NewULID().String(),
+		clock:   layeredStoreConfig.Clock,
+	}
+
+	// subscribe before returning so that no invalidation is missed
+	pubsub := client.Subscribe(ctx, ls.channel)
+	_, err = pubsub.Receive(ctx)
+	if err != nil {
+		_ = pubsub.Close()
+		return nil, datastore.Wrap(datastore.ErrConnection, "error subscribing to "+ls.channel, err)
+	}
+	subscriberCtx, cancel := context.WithCancel(context.Background())
+	ls.pubsub = pubsub
+	ls.cancel = cancel
+	ls.done.Add(1)
+	go ls.subscribe(subscriberCtx)
+	return ls, nil
+}
+
+// evicts the keys changed by the other instances from L1 until the store is closed |
+// L1 is flushed when the subscription breaks or is re-established since invalidations may have been missed
+func (ls *layeredStore) subscribe(ctx context.Context) {
+	defer ls.done.Done()
+	for {
+		msg, err := ls.pubsub.Receive(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			// go-redis reconnects and resubscribes on the next Receive
+			ls.flushL1(ctx)
+			if !ls.backoff(ctx) {
+				return
+			}
+			continue
+		}
+		switch msg := msg.(type) {
+		case *redis.Subscription:
+			ls.flushL1(ctx)
+		case *redis.Message:
+			var inv invalidation
+			if json.Unmarshal([]byte(msg.Payload), &inv) != nil || inv.Origin == ls.origin {
+				continue
+			}
+			for _, key := range inv.Keys {
+				ls.evictL1(ctx, key)
+				ls.invalidations.Add(1)
+			}
+		}
+	}
+}
+
+// waits before the next Receive, false is returned when ctx is done
+func (ls *layeredStore) backoff(ctx context.Context) bool {
+	timer := ls.clock.NewTimer(layered_resubscribe_backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C():
+		return true
+	}
+}
+
+// returns the invalidation generation of the shard of the key
+func (ls *layeredStore) generation(key string) *generation {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+	return &ls.generations[hash.Sum32()%layered_generation_shards]
+}
+
+// returns the current invalidation generation of the key, read it before the L2 read which fills L1
+func (ls *layeredStore) loadGeneration(key string) uint64 {
+	g := ls.generation(key)
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.value
+}
+
+// bumps the invalidation generation of the key so that the pending fills of older L2 reads are dropped
+func (ls *layeredStore) bumpGeneration(key string) uint64 {
+	g := ls.generation(key)
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.value++
+	return g.value
+}
+
+// evicts the key from L1, the generation is bumped first so that a fill racing with the eviction is dropped
+func (ls *layeredStore) evictL1(ctx context.Context, key string) {
+	ls.bumpGeneration(key)
+	_, _ = ls.l1.DeleteData(ctx, key)
+}
+
+// fills L1 with the data unless the key was invalidated since its generation was read, a failure only costs an L1 miss
+func (ls *layeredStore) fillL1(ctx context.Context, key string, gen uint64, value any, expiration time.Duration) {
+	g := ls.generation(key)
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.value != gen {
+		return
+	}
+	_, err := ls.l1.AddData(ctx, key, value, cache.WithExpiration(expiration))
+	if err != nil {
+		_, _ = ls.l1.DeleteData(ctx, key)
+	}
+}
+
+// drops every key of L1, every generation is bumped first so that no pending fill survives the flush
+func (ls *layeredStore) flushL1(ctx context.Context) {
+	for i := range ls.generations {
+		ls.generations[i].lock.Lock()
+		ls.generations[i].value++
+		ls.generations[i].lock.Unlock()
+	}
+	keys, err := ls.l1.GetKeys(ctx, "")
+	if err != nil {
+		return
+	}
+	for _, key := range keys {
+		_, _ = ls.l1.DeleteData(ctx, key)
+	}
+}
+
+// publishes the changed keys, a failed publish is bounded by L1TTL on the other instances
+func (ls *layeredStore) publish(ctx context.Context, keys ...string) {
+	payload, err := json.Marshal(invalidation{Origin: ls.origin, Keys: keys})
+	if err != nil {
+		return
+	}
+	_ = ls.client.Publish(ctx, ls.channel, payload).Err()
+}
+
+// Stats returns the hits and misses of every layer
+func (ls *layeredStore) Stats() Stats {
+	return Stats{
+		L1Hits:        ls.l1Hits.Load(),
+		L1Misses:      ls.l1Misses.Load(),
+		L2Hits:        ls.l2Hits.Load(),
+		L2Misses:      ls.l2Misses.Load(),
+		Invalidations: ls.invalidations.Load(),
+	}
+}
+
+// GetStats returns the stats of a cache created by NewLayeredStoreClient |
+// decorators (retry, circuitbreaker) are unwrapped through their Unwrap method
+func GetStats(c cache.Cache) (Stats, error) {
+	for c != nil {
+		if store, ok := c.(interface{ Stats() Stats }); ok {
+			return store.Stats(), nil
+		}
+		wrapper, ok := c.(interface{ Unwrap() cache.Cache })
+		if !ok {
+			break
+		}
+		c = wrapper.Unwrap()
+	}
+	return Stats{}, datastore.ErrInvalidArgs.WithMessage("cache is not a layered cache")
+}
+
+// checks the connection to L2 and returns error if any
+func (ls *layeredStore) HealthCheck(ctx context.Context) error {
+
+	// check if the client is closed
+	if ls.closed.Load() {
+		return datastore.ErrClosed
+	}
+	return ls.l2.HealthCheck(ctx)
+}
+
+// stops the subscription and closes both layers, calling it more than once is a no-op
+func (ls *layeredStore) Close(ctx context.Context) error {
+	if ls.closed.Swap(true) {
+		return nil
+	}
+	// closing the subscription unblocks the pending Receive
+	ls.cancel()
+	_ = ls.pubsub.Close()
+	ls.done.Wait()
+	return errors.Join(ls.l1.Close(ctx), ls.l2.Close(ctx))
+}
+
+// inserts data into L2 and L1 and evicts the key from the L1 of the other instances |
+// cache.WithExpiration can be passed after the value, L1 keeps the key for at most L1TTL
+func (ls *layeredStore) AddData(ctx context.Context, args ...any) (string, error) {
+
+	// check if the client is closed
+	if ls.closed.Load() {
+		return "", datastore.ErrClosed
+	}
+
+	// validate the passed args
+	if len(args) < 2 {
+		return "", datastore.ErrInvalidArgs.WithMessage("collection key or value is(are) missing")
+	}
+	options, err := cache.ParseOptions(args[2:]...)
+	if err != nil {
+		return "", err
+	}
+
+	// extract the key from args
+	key, ok := args[0].(string)
+	if !ok {
+		return "", datastore.ErrInvalidArgs.WithMessage("invalid key is passed (not a string)")
+	}
+
+	// add the data to L2
+	ack, err := ls.l2.AddData(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+
+	// add the data to L1, the pending fills of older L2 reads are dropped
+	ls.fillL1(ctx, key, ls.bumpGeneration(key), args[1], ls.l1Expiration(options.Expiration))
+	ls.publish(ctx, key)
+	return ack, nil
+}
+
+// gets the data from L1, or from L2 which then fills L1
+func (ls *layeredStore) GetData(ctx context.Context, args ...any) (any, error) {
+
+	// check if the client is closed
+	if ls.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
+
+	// validate the passed args
+	if len(args) != 1 {
+		return "", datastore.ErrInvalidArgs.WithMessage("a single collection key is expected")
+	}
+
+	// extract the key from args
+	key, ok := args[0].(string)
+	if !ok {
+		return "", datastore.ErrInvalidArgs.WithMessage("invalid key is passed (not a string)")
+	}
+
+	// get the data from L1
+	data, err := ls.l1.GetData(ctx, key)
+	if err == nil {
+		ls.l1Hits.Add(1)
+		return data, nil
+	}
+	ls.l1Misses.Add(1)
+
+	// get the data with its ttl from L2, the generation is read first so that an invalidation during the read drops the L1 fill
+	gen := ls.loadGeneration(key)
+	raw, ttl, err := redisstore.GetDataWithTTL(ctx, ls.l2, key)
+	if errors.Is(err, datastore.ErrNotFound) {
+		ls.l2Misses.Add(1)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	ls.l2Hits.Add(1)
+
+	// fill L1 for no longer than the key lives in L2
+	ls.fillL1(ctx, key, gen, json.RawMessage(raw), ls.l1Expiration(ttl))
+	return raw, nil
+}
+
+// gets the keys from L2, which has all of them
+func (ls *layeredStore) GetKeys(ctx context.Context, pattern string) ([]string, error) {
+
+	// check if the client is closed
+	if ls.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
+	return ls.l2.GetKeys(ctx, pattern)
+}
+
+// deletes the data from L2 and L1 and evicts the key from the L1 of the other instances
+func (ls *layeredStore) DeleteData(ctx context.Context, args ...any) (any, error) {
+
+	// check if the client is closed
+	if ls.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
+
+	// validate the passed args
+	if len(args) != 1 {
+		return "", datastore.ErrInvalidArgs.WithMessage("a single collection key is expected")
+	}
+
+	// extract the key from args
+	key, ok := args[0].(string)
+	if !ok {
+		return "", datastore.ErrInvalidArgs.WithMessage("invalid key is passed (not a string)")
+	}
+
+	// delete the data
+	res, err := ls.l2.DeleteData(ctx, key)
+	ls.evictL1(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	ls.publish(ctx, key)
+	return res, nil
+}
+
+// sets the time to live of the key in L2, the key is evicted from every L1
+func (ls *layeredStore) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+
+	// check if the client is closed
+	if ls.closed.Load() {
+		return false, datastore.ErrClosed
+	}
+
+	ok, err := ls.l2.Expire(ctx, key, expiration)
+	ls.evictL1(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	ls.publish(ctx, key)
+	return ok, nil
+}
+
+// returns the time to live of the key in L2
+func (ls *layeredStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	// check if the client is closed
+	if ls.closed.Load() {
+		return 0, datastore.ErrClosed
+	}
+	return ls.l2.TTL(ctx, key)
+}
+
+// removes the expiry of the key in L2, the key is evicted from every L1
+func (ls *layeredStore) Persist(ctx context.Context, key string) (bool, error) {
+
+	// check if the client is closed
+	if ls.closed.Load() {
+		return false, datastore.ErrClosed
+	}
+
+	ok, err := ls.l2.Persist(ctx, key)
+	ls.evictL1(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	ls.publish(ctx, key)
+	return ok, nil
+}
+
+// reports whether the key exists in L1 or L2
+func (ls *layeredStore) Exists(ctx context.Context, key string) (bool, error) {
+
+	// check if the client is closed
+	if ls.closed.Load() {
+		return false, datastore.ErrClosed
+	}
+	if ok, err := ls.l1.Exists(ctx, key); err == nil && ok {
+		return true, nil
+	}
+	return ls.l2.Exists(ctx, key)
+}
+
+// returns the expiration of a key in L1, capped at L1TTL
+func (ls *layeredStore) l1Expiration(expiration time.Duration) time.Duration {
+	if expiration <= 0 || expiration > ls.l1TTL {
+		return ls.l1TTL
+	}
+	return expiration
+}
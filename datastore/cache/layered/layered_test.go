@@ -0,0 +1,42 @@
+package layered
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gnanasuryateja/golib/datastore/cache/memory"
+)
+
+func TestFillL1IsDroppedAfterInvalidation(t *testing.T) {
+	ctx := context.Background()
+	l1, err := memory.NewMemoryStoreClient(ctx, memory.MemoryStoreConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l1.Close(ctx)
+	ls := &layeredStore{l1: l1, l1TTL: time.Minute}
+
+	// the key is invalidated between the L2 read and the fill
+	gen := ls.loadGeneration("key")
+	ls.evictL1(ctx, "key")
+	ls.fillL1(ctx, "key", gen, "stale", time.Minute)
+	if ok, _ := l1.Exists(ctx, "key"); ok {
+		t.Fatal("the fill of a read older than the invalidation was stored")
+	}
+
+	// a flush invalidates every key
+	gen = ls.loadGeneration("other")
+	ls.flushL1(ctx)
+	ls.fillL1(ctx, "other", gen, "stale", time.Minute)
+	if ok, _ := l1.Exists(ctx, "other"); ok {
+		t.Fatal("the fill of a read older than the flush was stored")
+	}
+
+	// a fill without invalidation is stored
+	gen = ls.loadGeneration("key")
+	ls.fillL1(ctx, "key", gen, "fresh", time.Minute)
+	if ok, _ := l1.Exists(ctx, "key"); !ok {
+		t.Fatal("the fill was dropped without an invalidation")
+	}
+}
@@ -0,0 +1,246 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/datastore"
+	"github.com/gnanasuryateja/golib/datastore/cache"
+	"github.com/gnanasuryateja/golib/errors"
+	"github.com/gnanasuryateja/golib/lock"
+)
+
+const (
+	lock_name_prefix     = "loader:"
+	namespace_divider    = ":"
+	default_lock_timeout = 5 * time.Second
+	default_load_timeout = 30 * time.Second
+)
+
+// the loads are collapsed per value type (one singleflight.Group per T), cache and namespaced key
+var groups sync.Map
+
+type options struct {
+	codec       cache.Codec
+	stale       time.Duration
+	negativeTTL time.Duration
+	locker      *lock.Locker
+	lockTimeout time.Duration
+	loadTimeout time.Duration
+	namespace   string
+	clock       clock.Clock
+}
+
+// Option configures GetOrLoad
+type Option func(o *options)
+
+// sets the codec of the cached entries, cache.JSONCodec by default
+func WithCodec(codec cache.Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// serves the value for stale after its ttl while it is reloaded in the background
+func WithStaleWhileRevalidate(stale time.Duration) Option {
+	return func(o *options) {
+		o.stale = stale
+	}
+}
+
+// caches the datastore.ErrNotFound results of the loader for ttl
+func WithNegativeCaching(ttl time.Duration) Option {
+	return func(o *options) {
+		o.negativeTTL = ttl
+	}
+}
+
+// holds the lock of the key while loading so that a single replica loads it, the others wait up to timeout (5s when zero)
+// and then read the cache, they load it themselves when the lock could not be acquired in time
+func WithLock(locker *lock.Locker, timeout time.Duration) Option {
+	return func(o *options) {
+		o.locker = locker
+		o.lockTimeout = timeout
+	}
+}
+
+// bounds the load and the store of the value (30s when zero), it is not cancelled with the ctx of a single caller
+func WithLoadTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.loadTimeout = timeout
+	}
+}
+
+// prefixes the key of the collapsed loads and of the WithLock name, e.g. so that the loaders of different caches sharing a
+// redis do not wait for each other's lock
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// sets the clock deciding the freshness of the entries, the real clock by default
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// entry is stored in the cache in place of the value, it records the freshness and the not found results
+type entry[T any] struct {
+	Value      T         `json:"value"`
+	NotFound   bool      `json:"not_found,omitempty"`
+	FreshUntil time.Time `json:"fresh_until"`
+}
+
+// returns the value of the key from the cache, or loads it with load and caches it for ttl on a miss |
+// the concurrent loads of a key (per WithNamespace) are collapsed into one, datastore.ErrNotFound from load is returned as is (and cached
+// with WithNegativeCaching) and the cache failures are ignored so that the loader still answers |
+// the keys hold an entry wrapping the value, so they must only be read through GetOrLoad
+func GetOrLoad[T any](ctx context.Context, c cache.Cache, key string, load func(ctx context.Context) (T, error), ttl time.Duration, opts ...Option) (T, error) {
+	var zero T
+
+	// validate the passed args
+	if c == nil || load == nil || key == "" {
+		return zero, datastore.ErrInvalidArgs.WithMessage("cache, key and load cannot be empty")
+	}
+	if ttl <= 0 {
+		return zero, datastore.ErrInvalidArgs.WithMessage("ttl must be positive")
+	}
+
+	// apply the options
+	o := options{
+		codec:       cache.JSONCodec,
+		lockTimeout: default_lock_timeout,
+		loadTimeout: default_load_timeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.clock = clock.OrReal(o.clock)
+	if o.lockTimeout <= 0 {
+		o.lockTimeout = default_lock_timeout
+	}
+	if o.loadTimeout <= 0 {
+		o.loadTimeout = default_load_timeout
+	}
+
+	name := key
+	if o.namespace != "" {
+		name = o.namespace + namespace_divider + key
+	}
+	l := &loader[T]{
+		entries:  cache.NewTypedCache[entry[T]](c, o.codec),
+		key:      key,
+		name:     name,
+		groupKey: cacheIdentity(c) + namespace_divider + name,
+		load:     load,
+		ttl:      ttl,
+		options:  o,
+	}
+
+	// serve the cached entry, the stale ones are reloaded in the background
+	cached, err := l.entries.Get(ctx, key)
+	if err == nil {
+		if cached.NotFound {
+			return zero, datastore.ErrNotFound.WithMessage("key " + key + " was not found by the loader (cached)")
+		}
+		if o.clock.Now().After(cached.FreshUntil) {
+			l.loadOnce(ctx)
+		}
+		return cached.Value, nil
+	}
+
+	// load the value once for all the concurrent callers
+	result := l.loadOnce(ctx)
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return res.Val.(T), nil
+	}
+}
+
+type loader[T any] struct {
+	entries  *cache.TypedCache[entry[T]]
+	key      string
+	name     string
+	groupKey string
+	load     func(ctx context.Context) (T, error)
+	ttl      time.Duration
+	options  options
+}
+
+// runs loadAndStore once for all the concurrent callers of the key |
+// the load is not cancelled with the ctx of a single caller, it is bounded by the load timeout instead
+func (l *loader[T]) loadOnce(ctx context.Context) <-chan singleflight.Result {
+	return groupOf[T]().DoChan(l.groupKey, func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.options.loadTimeout)
+		defer cancel()
+		return l.loadAndStore(loadCtx)
+	})
+}
+
+// returns the identity of the cache in the keys of the collapsed loads so that the loads of different caches are never shared |
+// it is the address of the pointer caches (every cache and decorator of the repo), the value caches are only told apart by
+// their type and need WithNamespace
+func cacheIdentity(c cache.Cache) string {
+	value := reflect.ValueOf(c)
+	if value.Kind() == reflect.Pointer {
+		return fmt.Sprintf("%T@%x", c, value.Pointer())
+	}
+	return fmt.Sprintf("%T", c)
+}
+
+// returns the singleflight group of T so that the loads of different types never share a result
+func groupOf[T any]() *singleflight.Group {
+	group, _ := groups.LoadOrStore(reflect.TypeOf((*T)(nil)).Elem(), &singleflight.Group{})
+	return group.(*singleflight.Group)
+}
+
+// loads the value and stores it in the cache, under the distributed lock when one is set
+func (l *loader[T]) loadAndStore(ctx context.Context) (T, error) {
+	if l.options.locker != nil {
+		lockCtx, cancel := context.WithTimeout(ctx, l.options.lockTimeout)
+		held, err := l.options.locker.Acquire(lockCtx, lock_name_prefix+l.name)
+		cancel()
+		if err == nil {
+			defer held.Release(ctx)
+
+			// another replica may have loaded the value while this one was waiting
+			cached, err := l.entries.Get(ctx, l.key)
+			if err == nil && cached.NotFound {
+				return cached.Value, datastore.ErrNotFound.WithMessage("key " + l.key + " was not found by the loader (cached)")
+			}
+			if err == nil && !l.options.clock.Now().After(cached.FreshUntil) {
+				return cached.Value, nil
+			}
+		}
+	}
+
+	// load the value
+	value, err := l.load(ctx)
+	if errors.Is(err, datastore.ErrNotFound) && l.options.negativeTTL > 0 {
+		_ = l.entries.Set(ctx, l.key, entry[T]{NotFound: true}, cache.WithExpiration(l.options.negativeTTL))
+		return value, err
+	}
+	if err != nil {
+		return value, err
+	}
+
+	// store the value, it is kept for the stale period after it stops being fresh
+	_ = l.entries.Set(ctx, l.key, entry[T]{
+		Value:      value,
+		FreshUntil: l.options.clock.Now().Add(l.ttl),
+	}, cache.WithExpiration(l.ttl+l.options.stale))
+	return value, nil
+}
@@ -0,0 +1,124 @@
+package loader
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gnanasuryateja/golib/datastore/cache"
+	"github.com/gnanasuryateja/golib/datastore/cache/memory"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+func newTestCache(t *testing.T) cache.Cache {
+	t.Helper()
+	c, err := memory.NewMemoryStoreClient(context.Background(), memory.MemoryStoreConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close(context.Background()) })
+	return c
+}
+
+func TestGetOrLoadCollapsesConcurrentLoads(t *testing.T) {
+	c := newTestCache(t)
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (string, error) {
+		loads.Add(1)
+		<-release
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := GetOrLoad(context.Background(), c, "collapsed", load, time.Minute, WithNamespace(t.Name()))
+			if err != nil || value != "value" {
+				t.Errorf("GetOrLoad() = %q, %v, want value", value, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := loads.Load(); got != 1 {
+		t.Errorf("loads = %d, want 1", got)
+	}
+}
+
+func TestGetOrLoadSeparatesTypes(t *testing.T) {
+	c := newTestCache(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	// the string load is in flight while the int load of the same key runs
+	done := make(chan string)
+	go func() {
+		value, _ := GetOrLoad(context.Background(), c, "shared", func(ctx context.Context) (string, error) {
+			close(started)
+			<-release
+			return "value", nil
+		}, time.Minute, WithNamespace(t.Name()))
+		done <- value
+	}()
+	<-started
+
+	number, err := GetOrLoad(context.Background(), c, "shared", func(ctx context.Context) (int, error) {
+		return 42, nil
+	}, time.Minute, WithNamespace(t.Name()))
+	if err != nil || number != 42 {
+		t.Errorf("GetOrLoad[int]() = %d, %v, want 42", number, err)
+	}
+	close(release)
+	if value := <-done; value != "value" {
+		t.Errorf("GetOrLoad[string]() = %q, want value", value)
+	}
+}
+
+func TestGetOrLoadTimeout(t *testing.T) {
+	c := newTestCache(t)
+	_, err := GetOrLoad(context.Background(), c, "hung", func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}, time.Minute, WithNamespace(t.Name()), WithLoadTimeout(10*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetOrLoad() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestGetOrLoadSeparatesCaches(t *testing.T) {
+	first, second := newTestCache(t), newTestCache(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	// the load of the first cache is in flight while the second cache loads the same key
+	done := make(chan string)
+	go func() {
+		value, _ := GetOrLoad(context.Background(), first, "key", func(ctx context.Context) (string, error) {
+			close(started)
+			<-release
+			return "first", nil
+		}, time.Minute)
+		done <- value
+	}()
+	<-started
+
+	value, err := GetOrLoad(context.Background(), second, "key", func(ctx context.Context) (string, error) {
+		return "second", nil
+	}, time.Minute)
+	if err != nil || value != "second" {
+		t.Errorf("GetOrLoad() of the second cache = %q, %v, want second", value, err)
+	}
+	close(release)
+	if value := <-done; value != "first" {
+		t.Errorf("GetOrLoad() of the first cache = %q, want first", value)
+	}
+	if ok, _ := second.Exists(context.Background(), "key"); !ok {
+		t.Error("the value was not stored in the second cache")
+	}
+}
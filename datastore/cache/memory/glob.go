@@ -0,0 +1,103 @@
+package memory
+
+// reports whether the key matches the redis style glob pattern |
+// * matches any sequence, ? any single byte, [abc] [a-z] [^a] a class and \ escapes the next byte
+func globMatch(pattern string, key string) bool {
+	px, kx := 0, 0
+
+	// position to restart from when a mismatch follows a *
+	starPx, starKx := -1, -1
+	for px < len(pattern) || kx < len(key) {
+		if px < len(pattern) {
+			switch c := pattern[px]; c {
+			case '*':
+				// try to match the empty sequence first, then one more byte on every restart
+				starPx, starKx = px, kx+1
+				px++
+				continue
+			case '?':
+				if kx < len(key) {
+					px++
+					kx++
+					continue
+				}
+			case '[':
+				if kx < len(key) {
+					matched, width, ok := matchClass(pattern[px:], key[kx])
+					if !ok {
+						// an unterminated class is matched literally
+						matched, width = key[kx] == '[', 1
+					}
+					if matched {
+						px += width
+						kx++
+						continue
+					}
+				}
+			case '\\':
+				if px+1 < len(pattern) {
+					c = pattern[px+1]
+					if kx < len(key) && key[kx] == c {
+						px += 2
+						kx++
+						continue
+					}
+					break
+				}
+				fallthrough
+			default:
+				if kx < len(key) && key[kx] == c {
+					px++
+					kx++
+					continue
+				}
+			}
+		}
+
+		// restart after the last * with one more byte consumed
+		if starKx > 0 && starKx <= len(key) {
+			px, kx = starPx, starKx
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// matches the byte against the class at the start of the pattern |
+// the width of the class is returned, ok is false when the class is not terminated
+func matchClass(pattern string, b byte) (matched bool, width int, ok bool) {
+	i := 1
+	negate := false
+	if i < len(pattern) && pattern[i] == '^' {
+		negate = true
+		i++
+	}
+	for i < len(pattern) && pattern[i] != ']' {
+		lo := pattern[i]
+		if lo == '\\' && i+1 < len(pattern) {
+			i++
+			lo = pattern[i]
+		}
+		hi := lo
+		if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+			hi = pattern[i+2]
+			if hi == '\\' && i+3 < len(pattern) {
+				i++
+				hi = pattern[i+2]
+			}
+			i += 2
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+		}
+		if lo <= b && b <= hi {
+			matched = true
+		}
+		i++
+	}
+	if i >= len(pattern) {
+		return false, 0, false
+	}
+	return matched != negate, i + 1, true
+}
@@ -0,0 +1,78 @@
+package memory
+
+import "testing"
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"user", "user", true},
+		{"user", "users", false},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"user:*", "user:42", true},
+		{"user:*", "user:", true},
+		{"user:*", "order:42", false},
+		{"*:42", "user:42", true},
+		{"a*b*c", "aXXbYYc", true},
+		{"a*b*c", "aXXbYY", false},
+		{"**", "abc", true},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h??llo", "heello", true},
+		{"?", "", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[a-c]llo", "hbllo", true},
+		{"h[c-a]llo", "hbllo", true},
+		{"h[a-c]llo", "hdllo", false},
+		{"h[^a-z]llo", "h1llo", true},
+		{"h[^a-z]llo", "hello", false},
+		{"h[^e]llo", "hallo", true},
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{`h\?llo`, "h?llo", true},
+		{`h\?llo`, "hallo", false},
+		{`h[\]]llo`, "h]llo", true},
+		{`\[a]`, "[a]", true},
+		{`trailing\`, `trailing\`, true},
+		{"h[ello", "h[ello", true},
+		{"h[ello", "hello", false},
+		{"*[", "abc[", true},
+		{"*[", "abc", false},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.key); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestMatchClass(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		b           byte
+		wantMatched bool
+		wantWidth   int
+		wantOK      bool
+	}{
+		{"[abc]", 'b', true, 5, true},
+		{"[abc]rest", 'd', false, 5, true},
+		{"[^abc]", 'd', true, 6, true},
+		{"[a-z]", 'm', true, 5, true},
+		{"[a-]", '-', true, 4, true},
+		{`[\-]`, '-', true, 4, true},
+		{"[abc", 'a', false, 0, false},
+		{"[", 'a', false, 0, false},
+	}
+	for _, tt := range tests {
+		matched, width, ok := matchClass(tt.pattern, tt.b)
+		if matched != tt.wantMatched || width != tt.wantWidth || ok != tt.wantOK {
+			t.Errorf("matchClass(%q, %q) = (%v, %d, %v), want (%v, %d, %v)", tt.pattern, tt.b, matched, width, ok, tt.wantMatched, tt.wantWidth, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/datastore"
+	cache "github.com/gnanasuryateja/golib/datastore/cache"
+	"github.com/gnanasuryateja/golib/validation"
+)
+
+const (
+	memory_default_janitor_interval    = time.Minute
+	memory_add_success_acknowledgement = "Sucessfully added to memory...:)"
+)
+
+type MemoryStoreConfig struct {
+	MaxEntries      int           `validate:"min=0"` // MaxEntries evicts the least recently used keys above it, unlimited when zero |
+	MaxBytes        int64         `validate:"min=0"` // MaxBytes evicts the least recently used keys when the keys and json values exceed it, unlimited when zero |
+	DefaultTTL      time.Duration `validate:"min=0"` // DefaultTTL is the expiry of the keys added without one, no expiry when zero |
+	JanitorInterval time.Duration `validate:"min=0"` // JanitorInterval is the period of the removal of the expired keys, 1m by default |
+	Clock           clock.Clock   `validate:"-"`     // Clock drives the expiry and the janitor, the real clock by default
+}
+
+// validates the input params
+func (msc MemoryStoreConfig) validate() error {
+	err := validation.Validate(msc)
+	if err != nil {
+		return datastore.Wrap(datastore.ErrInvalidArgs, "invalid MemoryStoreConfig", err)
+	}
+	return nil
+}
+
+type entry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// returns the size of the entry counted against MaxBytes
+func (e *entry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+// reports whether the entry expired at now
+func (e *entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+type memoryStore struct {
+	config MemoryStoreConfig
+	clock  clock.Clock
+	lock   sync.Mutex
+	items  map[string]*list.Element
+	lru    *list.List
+	bytes  int64
+	closed atomic.Bool
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// creates a new memoryStore client, the values are stored as json like redisStore does
+func NewMemoryStoreClient(ctx context.Context, memoryStoreConfig MemoryStoreConfig) (cache.Cache, error) {
+
+	// validate the memoryStoreConfig
+	err := memoryStoreConfig.validate()
+	if err != nil {
+		return nil, err
+	}
+	if memoryStoreConfig.JanitorInterval == 0 {
+		memoryStoreConfig.JanitorInterval = memory_default_janitor_interval
+	}
+
+	ms := &memoryStore{
+		config: memoryStoreConfig,
+		clock:  clock.OrReal(memoryStoreConfig.Clock),
+		items:  map[string]*list.Element{},
+		lru:    list.New(),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	// start the janitor
+	go ms.janitor()
+	return ms, nil
+}
+
+// removes the expired keys every JanitorInterval until the store is closed
+func (ms *memoryStore) janitor() {
+	defer close(ms.done)
+	ticker := ms.clock.NewTicker(ms.config.JanitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ms.stop:
+			return
+		case <-ticker.C():
+		}
+		ms.lock.Lock()
+		now := ms.clock.Now()
+		for _, element := range ms.items {
+			if element.Value.(*entry).expired(now) {
+				ms.remove(element)
+			}
+		}
+		ms.lock.Unlock()
+	}
+}
+
+// checks the store and returns error if it is closed
+func (ms *memoryStore) HealthCheck(ctx context.Context) error {
+
+	// check if the client is closed
+	if ms.closed.Load() {
+		return datastore.ErrClosed
+	}
+	return nil
+}
+
+// stops the janitor and drops all the keys, calling it more than once is a no-op
+func (ms *memoryStore) Close(ctx context.Context) error {
+	if ms.closed.Swap(true) {
+		return nil
+	}
+	close(ms.stop)
+	<-ms.done
+
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	ms.items = map[string]*list.Element{}
+	ms.lru.Init()
+	ms.bytes = 0
+	return nil
+}
+
+// inserts data into cache, cache.WithExpiration can be passed after the value to set the time to live of the key (DefaultTTL otherwise)
+func (ms *memoryStore) AddData(ctx context.Context, args ...any) (string, error) {
+
+	// check if the client is closed
+	if ms.closed.Load() {
+		return "", datastore.ErrClosed
+	}
+
+	// validate the passed args
+	if len(args) < 2 {
+		return "", datastore.ErrInvalidArgs.WithMessage("collection key or value is(are) missing")
+	}
+	options, err := cache.ParseOptions(args[2:]...)
+	if err != nil {
+		return "", err
+	}
+
+	// extract the key from args
+	key, ok := args[0].(string)
+	if !ok {
+		return "", datastore.ErrInvalidArgs.WithMessage("invalid key is passed (not a string)")
+	}
+
+	// get the ttl from the options
+	ttl := options.Expiration
+	if ttl == 0 {
+		ttl = ms.config.DefaultTTL
+	}
+
+	// encode the value as json, the same way JSON.SET receives it
+	value, err := json.Marshal(args[1])
+	if err != nil {
+		return "", datastore.Wrap(datastore.ErrInvalidArgs, "error encoding the data", err)
+	}
+	newEntry := &entry{
+		key:   key,
+		value: value,
+	}
+	if ttl > 0 {
+		newEntry.expiresAt = ms.clock.Now().Add(ttl)
+	}
+	if ms.config.MaxBytes > 0 && newEntry.size() > ms.config.MaxBytes {
+		return "", datastore.ErrInvalidArgs.WithMessage("data is larger than MaxBytes")
+	}
+
+	// add the key value and evict the least recently used keys above the limits
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	if element, ok := ms.items[key]; ok {
+		ms.remove(element)
+	}
+	ms.items[key] = ms.lru.PushFront(newEntry)
+	ms.bytes += newEntry.size()
+	for (ms.config.MaxEntries > 0 && ms.lru.Len() > ms.config.MaxEntries) || (ms.config.MaxBytes > 0 && ms.bytes > ms.config.MaxBytes) {
+		ms.remove(ms.lru.Back())
+	}
+
+	// return the acknowledgement
+	return memory_add_success_acknowledgement, nil
+}
+
+// gets the data from cache as json bytes
+func (ms *memoryStore) GetData(ctx context.Context, args ...any) (any, error) {
+
+	// check if the client is closed
+	if ms.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
+
+	// validate the passed args
+	if len(args) < 1 {
+		return "", datastore.ErrInvalidArgs.WithMessage("collection key or value is(are) missing")
+	}
+	if len(args) > 1 {
+		return "", datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
+	}
+
+	// extract the key from args
+	key, ok := args[0].(string)
+	if !ok {
+		return "", datastore.ErrInvalidArgs.WithMessage("invalid key is passed (not a string)")
+	}
+
+	// get the data
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	element, ok := ms.lookup(key)
+	if !ok {
+		return nil, datastore.ErrNotFound.WithMessage("error getting the data")
+	}
+	ms.lru.MoveToFront(element)
+	value := element.Value.(*entry).value
+
+	// return a copy of the data
+	data := make([]byte, len(value))
+	copy(data, value)
+	return data, nil
+}
+
+// gets the keys matching the redis style glob pattern from cache, all the keys when the pattern is empty
+func (ms *memoryStore) GetKeys(ctx context.Context, pattern string) ([]string, error) {
+
+	// check if the client is closed
+	if ms.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
+
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	now := ms.clock.Now()
+	keys := []string{}
+	for key, element := range ms.items {
+		if element.Value.(*entry).expired(now) {
+			continue
+		}
+		if pattern == "" || globMatch(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// deletes the data from cache, the number of deleted keys (int64) is returned like JSON.DEL
+func (ms *memoryStore) DeleteData(ctx context.Context, args ...any) (any, error) {
+
+	// check if the client is closed
+	if ms.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
+
+	// validate the passed args
+	if len(args) < 1 {
+		return "", datastore.ErrInvalidArgs.WithMessage("collection key or value is(are) missing")
+	}
+	if len(args) > 1 {
+		return "", datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
+	}
+
+	// extract the key from args
+	key, ok := args[0].(string)
+	if !ok {
+		return "", datastore.ErrInvalidArgs.WithMessage("invalid key is passed (not a string)")
+	}
+
+	// delete the data
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	element, ok := ms.lookup(key)
+	if !ok {
+		return int64(0), nil
+	}
+	ms.remove(element)
+	return int64(1), nil
+}
+
+// sets the time to live of the key, false is returned when the key does not exist |
+// a non positive expiration deletes the key like redis does
+func (ms *memoryStore) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+
+	// check if the client is closed
+	if ms.closed.Load() {
+		return false, datastore.ErrClosed
+	}
+
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	element, ok := ms.lookup(key)
+	if !ok {
+		return false, nil
+	}
+	if expiration <= 0 {
+		ms.remove(element)
+		return true, nil
+	}
+	element.Value.(*entry).expiresAt = ms.clock.Now().Add(expiration)
+	return true, nil
+}
+
+// returns the time to live of the key, cache.NoExpiration when it never expires and datastore.ErrNotFound when it does not exist
+func (ms *memoryStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	// check if the client is closed
+	if ms.closed.Load() {
+		return 0, datastore.ErrClosed
+	}
+
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	element, ok := ms.lookup(key)
+	if !ok {
+		return 0, datastore.ErrNotFound.WithMessage("key " + key + " does not exist")
+	}
+	expiresAt := element.Value.(*entry).expiresAt
+	if expiresAt.IsZero() {
+		return cache.NoExpiration, nil
+	}
+	return expiresAt.Sub(ms.clock.Now()), nil
+}
+
+// removes the expiry of the key, false is returned when the key does not exist or has no expiry
+func (ms *memoryStore) Persist(ctx context.Context, key string) (bool, error) {
+
+	// check if the client is closed
+	if ms.closed.Load() {
+		return false, datastore.ErrClosed
+	}
+
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	element, ok := ms.lookup(key)
+	if !ok || element.Value.(*entry).expiresAt.IsZero() {
+		return false, nil
+	}
+	element.Value.(*entry).expiresAt = time.Time{}
+	return true, nil
+}
+
+// reports whether the key exists
+func (ms *memoryStore) Exists(ctx context.Context, key string) (bool, error) {
+
+	// check if the client is closed
+	if ms.closed.Load() {
+		return false, datastore.ErrClosed
+	}
+
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	_, ok := ms.lookup(key)
+	return ok, nil
+}
+
+// returns the element of the key, expired keys are removed, the caller holds ms.lock
+func (ms *memoryStore) lookup(key string) (*list.Element, bool) {
+	element, ok := ms.items[key]
+	if !ok {
+		return nil, false
+	}
+	if element.Value.(*entry).expired(ms.clock.Now()) {
+		ms.remove(element)
+		return nil, false
+	}
+	return element, true
+}
+
+// removes the element, the caller holds ms.lock
+func (ms *memoryStore) remove(element *list.Element) {
+	removed := ms.lru.Remove(element).(*entry)
+	delete(ms.items, removed.key)
+	ms.bytes -= removed.size()
+}
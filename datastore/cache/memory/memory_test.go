@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/datastore"
+	cache "github.com/gnanasuryateja/golib/datastore/cache"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+func newTestStore(t *testing.T, config MemoryStoreConfig) *memoryStore {
+	t.Helper()
+	c, err := NewMemoryStoreClient(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close(context.Background()) })
+	return c.(*memoryStore)
+}
+
+func mustAdd(t *testing.T, ms *memoryStore, key string, value any, args ...any) {
+	t.Helper()
+	if _, err := ms.AddData(context.Background(), append([]any{key, value}, args...)...); err != nil {
+		t.Fatalf("AddData(%q) error: %v", key, err)
+	}
+}
+
+func keys(t *testing.T, ms *memoryStore) []string {
+	t.Helper()
+	keys, err := ms.GetKeys(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func equal(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEvictionOrder(t *testing.T) {
+	ms := newTestStore(t, MemoryStoreConfig{MaxEntries: 3})
+	ctx := context.Background()
+	mustAdd(t, ms, "a", 1)
+	mustAdd(t, ms, "b", 2)
+	mustAdd(t, ms, "c", 3)
+
+	// reading a makes b the least recently used key
+	if _, err := ms.GetData(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	mustAdd(t, ms, "d", 4)
+	if got, want := keys(t, ms), []string{"a", "c", "d"}; !equal(got, want) {
+		t.Fatalf("keys after the first eviction = %v, want %v", got, want)
+	}
+
+	// overwriting c makes it the most recently used key
+	mustAdd(t, ms, "c", 30)
+	mustAdd(t, ms, "e", 5)
+	if got, want := keys(t, ms), []string{"c", "d", "e"}; !equal(got, want) {
+		t.Fatalf("keys after the second eviction = %v, want %v", got, want)
+	}
+}
+
+func TestMaxBytesAfterOverwrite(t *testing.T) {
+	// every key is 1 byte and every json value "xxxx" is 6 bytes
+	ms := newTestStore(t, MemoryStoreConfig{MaxBytes: 21})
+	mustAdd(t, ms, "a", "xxxx")
+	mustAdd(t, ms, "b", "xxxx")
+	mustAdd(t, ms, "c", "xxxx")
+	if ms.bytes != 21 {
+		t.Fatalf("bytes = %d, want 21", ms.bytes)
+	}
+
+	// the overwritten value is not counted twice
+	mustAdd(t, ms, "a", "yyyy")
+	if ms.bytes != 21 {
+		t.Fatalf("bytes after the overwrite = %d, want 21", ms.bytes)
+	}
+	if got, want := keys(t, ms), []string{"a", "b", "c"}; !equal(got, want) {
+		t.Fatalf("keys after the overwrite = %v, want %v", got, want)
+	}
+
+	// a larger value evicts the least recently used keys until it fits
+	mustAdd(t, ms, "a", "yyyyyyyyyy")
+	if got, want := keys(t, ms), []string{"a", "c"}; !equal(got, want) {
+		t.Fatalf("keys after the larger overwrite = %v, want %v", got, want)
+	}
+	if ms.bytes != 20 {
+		t.Fatalf("bytes after the larger overwrite = %d, want 20", ms.bytes)
+	}
+
+	// values larger than MaxBytes are rejected
+	_, err := ms.AddData(context.Background(), "big", "yyyyyyyyyyyyyyyyyyyy")
+	if !errors.Is(err, datastore.ErrInvalidArgs) {
+		t.Fatalf("AddData() of a value larger than MaxBytes error = %v, want ErrInvalidArgs", err)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	ms := newTestStore(t, MemoryStoreConfig{DefaultTTL: time.Minute, Clock: fake})
+	ctx := context.Background()
+	mustAdd(t, ms, "default", 1)
+	mustAdd(t, ms, "short", 1, cache.WithExpiration(10*time.Second))
+	mustAdd(t, ms, "persisted", 1)
+	if ok, err := ms.Persist(ctx, "persisted"); err != nil || !ok {
+		t.Fatalf("Persist() = %v, %v, want true", ok, err)
+	}
+
+	if ttl, err := ms.TTL(ctx, "short"); err != nil || ttl != 10*time.Second {
+		t.Fatalf("TTL(short) = %v, %v, want 10s", ttl, err)
+	}
+	if ttl, err := ms.TTL(ctx, "persisted"); err != nil || ttl != cache.NoExpiration {
+		t.Fatalf("TTL(persisted) = %v, %v, want NoExpiration", ttl, err)
+	}
+
+	fake.Advance(10 * time.Second)
+	if _, err := ms.GetData(ctx, "short"); !errors.Is(err, datastore.ErrNotFound) {
+		t.Fatalf("GetData(short) after its ttl error = %v, want ErrNotFound", err)
+	}
+	if ttl, err := ms.TTL(ctx, "default"); err != nil || ttl != 50*time.Second {
+		t.Fatalf("TTL(default) = %v, %v, want 50s", ttl, err)
+	}
+
+	fake.Advance(50 * time.Second)
+	if got, want := keys(t, ms), []string{"persisted"}; !equal(got, want) {
+		t.Fatalf("keys after the default ttl = %v, want %v", got, want)
+	}
+	if ok, err := ms.Exists(ctx, "default"); err != nil || ok {
+		t.Fatalf("Exists(default) = %v, %v, want false", ok, err)
+	}
+}
+
+func TestExpireDeletesWithNonPositiveExpiration(t *testing.T) {
+	ms := newTestStore(t, MemoryStoreConfig{})
+	ctx := context.Background()
+	mustAdd(t, ms, "a", 1)
+	if ok, err := ms.Expire(ctx, "a", 0); err != nil || !ok {
+		t.Fatalf("Expire(a, 0) = %v, %v, want true", ok, err)
+	}
+	if ok, _ := ms.Exists(ctx, "a"); ok {
+		t.Fatal("the key still exists after Expire(a, 0)")
+	}
+	if ms.bytes != 0 {
+		t.Fatalf("bytes = %d, want 0", ms.bytes)
+	}
+}
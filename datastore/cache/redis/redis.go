@@ -4,69 +4,170 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	redigo "github.com/gomodule/redigo/redis"
 	rejson "github.com/nitishm/go-rejson/v4"
 	redis "github.com/redis/go-redis/v9"
 
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/datastore"
 	cache "github.com/gnanasuryateja/golib/datastore/cache"
+	"github.com/gnanasuryateja/golib/datastore/connstring"
+	"github.com/gnanasuryateja/golib/secrets"
+	"github.com/gnanasuryateja/golib/validation"
 )
 
 type RedisStoreConfig struct {
-	Addr     string
-	Port     string
-	Username string
-	Password string
-	CA       *string
-	CRT      *string
-	Key      *string
-	DB       *int
+	Addr     string  `config:"addr,required" validate:"required"`
+	Port     string  `config:"port,required" validate:"required"`
+	Username string  `config:"username,required" validate:"required"`
+	Password string  `config:"password,required" validate:"required"`
+	CA       *string `config:"ca" validate:"omitnil,required"` // CA, CRT and Key may be secret references, so they are not checked with file-exists |
+	CRT      *string `config:"crt" validate:"omitnil,required"`
+	Key      *string `config:"key" validate:"omitnil,required"`
+	DB       *int    `config:"db" validate:"omitnil,min=0"`
+
+	// SecretResolver resolves the Username, Password, CA, CRT and Key references (env://, file://, base64://, ...) |
+	// secrets.NewResolver() is used when nil
+	SecretResolver *secrets.Resolver `config:"-"`
 }
 
 // validates the input params
 func (rsc RedisStoreConfig) validate() error {
-	if rsc.Addr == "" || rsc.Port == "" || rsc.Username == "" || rsc.Password == "" {
-		return fmt.Errorf("RedisStoreConfig cannot have empty values")
-	}
-	if rsc.CA != nil && *rsc.CA == "" {
-		return fmt.Errorf("CA cannot be empty value")
-	}
-	if rsc.CRT != nil && *rsc.CRT == "" {
-		return fmt.Errorf("CRT cannot be empty value")
-	}
-	if rsc.Key != nil && *rsc.Key == "" {
-		return fmt.Errorf("key cannot be empty value")
+	err := validation.Validate(rsc)
+	if err != nil {
+		return datastore.Wrap(datastore.ErrInvalidArgs, "invalid RedisStoreConfig", err)
 	}
 	return nil
 }
 
+// prints the config as a redis url with the password redacted
+func (rsc RedisStoreConfig) String() string {
+	db := redis_default_db
+	if rsc.DB != nil {
+		db = *rsc.DB
+	}
+	return connstring.New(redis_uri_template).
+		Credentials(rsc.Username, rsc.Password).
+		Set(constants.HOST, connstring.HostPort(rsc.Addr, rsc.Port)).
+		Set(constants.DATABASE, strconv.Itoa(db)).
+		Redacted()
+}
+
 const (
+	redis_uri_template                = "redis://<username>:<password>@<host>/<database>"
 	redis_ping_str                    = "ping: PONG"
 	redis_default_db                  = 0
 	redis_add_success_acknowledgement = "Sucessfully added to redis...:)"
+	redis_json_set_cmd                = "JSON.SET"
+	redis_json_get_cmd                = "JSON.GET"
+	redis_json_root_path              = "."
 )
 
+// wraps the redis driver errors into the datastore errors, it is shared by the redis backed packages |
+// only the network failures map to ErrConnection, command errors (WRONGTYPE, NOSCRIPT, ...) keep their message
+func WrapError(err error, message string) error {
+	if err == nil {
+		return datastore.ErrConnection.WithMessage(message)
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, redis.Nil) || errors.Is(err, redigo.ErrNil):
+		return datastore.Wrap(datastore.ErrNotFound, message, err)
+	case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()):
+		return datastore.Wrap(datastore.ErrTimeout, message, err)
+	case errors.As(err, &netErr) || errors.Is(err, redis.ErrClosed) || errors.Is(err, io.EOF):
+		return datastore.Wrap(datastore.ErrConnection, message, err)
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
 type redisStore struct {
 	client        *redis.Client
 	rejsonHandler *rejson.Handler
+	closed        atomic.Bool
 }
 
 // creates a new redisStore client
 func NewRedisStoreClient(ctx context.Context, redisStoreConfig RedisStoreConfig) (cache.Cache, error) {
 
+	// create a new redis client
+	client, err := NewRedisClient(ctx, redisStoreConfig)
+	if err != nil {
+		return nil, err
+	}
+	rjhandler := rejson.NewReJSONHandler()
+	rjhandler.SetGoRedisClientWithContext(ctx, client)
+	return &redisStore{
+		rejsonHandler: rjhandler,
+		client:        client,
+	}, nil
+}
+
+// creates a new go-redis client from the config and checks the connection |
+// it is shared by the redis backed packages (cache, messaging queue) to get the same auth, tls and db setup
+func NewRedisClient(ctx context.Context, redisStoreConfig RedisStoreConfig) (*redis.Client, error) {
+
+	// build the options
+	redisOptions, err := NewRedisOptions(ctx, redisStoreConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a new redis client
+	client := redis.NewClient(redisOptions)
+
+	// check if the connection is successful
+	ping := client.Ping(ctx)
+	if ping.String() != redis_ping_str {
+		_ = client.Close()
+		return nil, WrapError(ping.Err(), "error connecting to redis")
+	}
+	return client, nil
+}
+
+// builds the go-redis options (address, credentials, db and tls) from the config
+func NewRedisOptions(ctx context.Context, redisStoreConfig RedisStoreConfig) (*redis.Options, error) {
+
 	// validate the redisStoreConfig
 	err := redisStoreConfig.validate()
 	if err != nil {
 		return nil, err
 	}
 
-	redisUri := fmt.Sprintf("%v:%v", redisStoreConfig.Addr, redisStoreConfig.Port)
+	// get the secret resolver
+	resolver := redisStoreConfig.SecretResolver
+	if resolver == nil {
+		resolver = secrets.NewResolver()
+	}
+
+	// resolve the credentials at connect time so that bad references fail early
+	username, password, err := resolveCredentials(ctx, resolver, redisStoreConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	redisUri := connstring.HostPort(redisStoreConfig.Addr, redisStoreConfig.Port)
 	redisOptions := redis.Options{
 		Addr:     redisUri,
-		Username: redisStoreConfig.Username,
-		Password: redisStoreConfig.Password,
+		Username: username,
+		Password: password,
+	}
+
+	// the referenced credentials are resolved for every new connection to pick up the rotated secrets
+	if resolver.IsReference(redisStoreConfig.Username) || resolver.IsReference(redisStoreConfig.Password) {
+		redisOptions.CredentialsProviderContext = func(ctx context.Context) (string, string, error) {
+			return resolveCredentials(ctx, resolver, redisStoreConfig)
+		}
 	}
 	if redisStoreConfig.DB != nil {
 		redisOptions.DB = *redisStoreConfig.DB
@@ -77,22 +178,27 @@ func NewRedisStoreClient(ctx context.Context, redisStoreConfig RedisStoreConfig)
 	if redisStoreConfig.CA != nil && *redisStoreConfig.CA != "" && redisStoreConfig.CRT != nil && *redisStoreConfig.CRT != "" && redisStoreConfig.Key != nil && *redisStoreConfig.Key != "" {
 
 		// Load client certificate and key
-		cert, err := tls.LoadX509KeyPair(*redisStoreConfig.CRT, *redisStoreConfig.Key)
+		crt, key := *redisStoreConfig.CRT, *redisStoreConfig.Key
+		_, err := loadKeyPair(ctx, resolver, crt, key)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load client certificate and key: %v", err)
+			return nil, datastore.Wrap(datastore.ErrInvalidArgs, "failed to load client certificate and key", err)
 		}
 
-		// create a tls config
+		// create a tls config, the client certificate is loaded on every handshake to pick up the rotated certificates
 		tlsConfig := tls.Config{
-			Certificates: []tls.Certificate{
-				cert,
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				cert, err := loadKeyPair(context.Background(), resolver, crt, key)
+				if err != nil {
+					return nil, err
+				}
+				return &cert, nil
 			},
 		}
 
 		// read the CA certificate
-		caCert, err := os.ReadFile(*redisStoreConfig.CA)
+		caCert, err := loadMaterial(ctx, resolver, *redisStoreConfig.CA)
 		if err != nil {
-			return nil, err
+			return nil, datastore.Wrap(datastore.ErrInvalidArgs, "failed to read the CA certificate", err)
 		}
 		caCertPool := x509.NewCertPool()
 		caCertPool.AppendCertsFromPEM(caCert)
@@ -100,56 +206,195 @@ func NewRedisStoreClient(ctx context.Context, redisStoreConfig RedisStoreConfig)
 		redisOptions.TLSConfig = &tlsConfig
 	}
 
-	// create a new redis client
-	client := redis.NewClient(&redisOptions)
+	return &redisOptions, nil
+}
 
-	// check if the connection is successful
-	ping := client.Ping(ctx)
-	if ping.String() != redis_ping_str {
-		return nil, ping.Err()
+// resolves the username and password references
+func resolveCredentials(ctx context.Context, resolver *secrets.Resolver, redisStoreConfig RedisStoreConfig) (string, string, error) {
+	username, err := resolver.ResolveString(ctx, redisStoreConfig.Username)
+	if err != nil {
+		return "", "", datastore.Wrap(datastore.ErrInvalidArgs, "failed to resolve the username", err)
 	}
-	rjhandler := rejson.NewReJSONHandler()
-	rjhandler.SetGoRedisClientWithContext(ctx, client)
-	return &redisStore{
-		rejsonHandler: rjhandler,
-		client:        client,
-	}, nil
+	password, err := resolver.ResolveString(ctx, redisStoreConfig.Password)
+	if err != nil {
+		return "", "", datastore.Wrap(datastore.ErrInvalidArgs, "failed to resolve the password", err)
+	}
+	return username, password, nil
+}
+
+// loads the tls material, references are resolved and anything else is read as a file path
+func loadMaterial(ctx context.Context, resolver *secrets.Resolver, value string) ([]byte, error) {
+	if resolver.IsReference(value) {
+		return resolver.Resolve(ctx, value)
+	}
+	return os.ReadFile(value)
+}
+
+// loads the client certificate and key
+func loadKeyPair(ctx context.Context, resolver *secrets.Resolver, crt string, key string) (tls.Certificate, error) {
+	crtPEM, err := loadMaterial(ctx, resolver, crt)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyPEM, err := loadMaterial(ctx, resolver, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(crtPEM, keyPEM)
+}
+
+// RedisClient returns the underlying go-redis client, e.g. to run Lua scripts
+func (rs *redisStore) RedisClient() (*redis.Client, error) {
+
+	// check if the client is closed
+	if rs.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
+	return rs.client, nil
+}
+
+// GetRedisClient returns the go-redis client of a cache created by NewRedisStoreClient |
+// decorators (retry, circuitbreaker) are unwrapped through their Unwrap method
+func GetRedisClient(c cache.Cache) (*redis.Client, error) {
+	for c != nil {
+		if store, ok := c.(interface{ RedisClient() (*redis.Client, error) }); ok {
+			return store.RedisClient()
+		}
+		wrapper, ok := c.(interface{ Unwrap() cache.Cache })
+		if !ok {
+			break
+		}
+		c = wrapper.Unwrap()
+	}
+	return nil, datastore.ErrInvalidArgs.WithMessage("cache is not a redis cache")
+}
+
+// GetDataWithTTL returns the data of a cache created by NewRedisStoreClient with its time to live in a single round trip |
+// the decorators (retry, circuitbreaker) intercept the call, the ttl is cache.NoExpiration when the key never expires
+func GetDataWithTTL(ctx context.Context, c cache.Cache, key string) ([]byte, time.Duration, error) {
+	for c != nil {
+		if store, ok := c.(interface {
+			GetDataWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error)
+		}); ok {
+			return store.GetDataWithTTL(ctx, key)
+		}
+		wrapper, ok := c.(interface{ Unwrap() cache.Cache })
+		if !ok {
+			break
+		}
+		c = wrapper.Unwrap()
+	}
+	return nil, 0, datastore.ErrInvalidArgs.WithMessage("cache is not a redis cache")
+}
+
+// gets the data and the ttl of the key, JSON.GET and PTTL are sent in one pipeline
+func (rs *redisStore) GetDataWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+
+	// check if the client is closed
+	if rs.closed.Load() {
+		return nil, 0, datastore.ErrClosed
+	}
+
+	// get the data and the ttl
+	var get *redis.Cmd
+	var pttl *redis.DurationCmd
+	_, err := rs.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Do(ctx, redis_json_get_cmd, key, redis_json_root_path)
+		pttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, WrapError(err, "error getting the data")
+	}
+	data, err := get.Text()
+	if err != nil {
+		return nil, 0, WrapError(err, "error getting the data")
+	}
+	ttl, err := pttl.Result()
+	if err != nil {
+		return nil, 0, WrapError(err, "error getting the ttl")
+	}
+	switch ttl {
+	case -2:
+		return nil, 0, datastore.ErrNotFound.WithMessage("key " + key + " does not exist")
+	case -1:
+		ttl = cache.NoExpiration
+	}
+	return []byte(data), ttl, nil
 }
 
 // checks the connection to cache and returns error if any
 func (rs *redisStore) HealthCheck(ctx context.Context) error {
+
+	// check if the client is closed
+	if rs.closed.Load() {
+		return datastore.ErrClosed
+	}
+
 	// check the connection
 	ping := rs.client.Ping(ctx)
 	if ping.String() == redis_ping_str {
 		return nil
 	}
-	return fmt.Errorf("HealthCheck failed for Redis: %v", ping.Err())
+	return WrapError(ping.Err(), "HealthCheck failed for Redis")
+}
+
+// closes the connections to cache, calling it more than once is a no-op
+func (rs *redisStore) Close(ctx context.Context) error {
+	if rs.closed.Swap(true) {
+		return nil
+	}
+	err := rs.client.Close()
+	if err != nil && !errors.Is(err, redis.ErrClosed) {
+		return WrapError(err, "error closing redis")
+	}
+	return nil
 }
 
-// inserts data into cache
+// inserts data into cache, cache.WithExpiration can be passed after the value to set the time to live of the key
 func (rs *redisStore) AddData(ctx context.Context, args ...any) (string, error) {
 
+	// check if the client is closed
+	if rs.closed.Load() {
+		return "", datastore.ErrClosed
+	}
+
 	// validate the passed args
 	if len(args) < 2 {
-		return "", fmt.Errorf("collection key or value is(are) missing")
+		return "", datastore.ErrInvalidArgs.WithMessage("collection key or value is(are) missing")
 	}
-	if len(args) > 2 {
-		return "", fmt.Errorf("more params are passed than expected")
+	options, err := cache.ParseOptions(args[2:]...)
+	if err != nil {
+		return "", err
 	}
 
 	// extract the key from args
 	key, ok := args[0].(string)
 	if !ok {
-		return "", fmt.Errorf("invalid key is passed (not a string)")
+		return "", datastore.ErrInvalidArgs.WithMessage("invalid key is passed (not a string)")
 	}
 
 	// extract the value from args
 	value := args[1]
 
-	// add the key value to redis
-	_, err := rs.rejsonHandler.JSONSet(key, ".", value)
+	// encode the value as json, the same way the rejson handler does
+	data, err := json.Marshal(value)
 	if err != nil {
-		return "", err
+		return "", datastore.Wrap(datastore.ErrInvalidArgs, "error encoding the data", err)
+	}
+
+	// add the key value to redis and set (or clear) its expiry in the same transaction
+	_, err = rs.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Do(ctx, redis_json_set_cmd, key, redis_json_root_path, string(data))
+		if options.Expiration > 0 {
+			pipe.PExpire(ctx, key, options.Expiration)
+		} else {
+			pipe.Persist(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", WrapError(err, "error adding the data")
 	}
 
 	// return the acknowledgement
@@ -159,26 +404,43 @@ func (rs *redisStore) AddData(ctx context.Context, args ...any) (string, error)
 // gets the data from cache
 func (rs *redisStore) GetData(ctx context.Context, args ...any) (any, error) {
 
+	// check if the client is closed
+	if rs.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
+
 	// validate the passed args
 	if len(args) < 1 {
-		return "", fmt.Errorf("collection key or value is(are) missing")
+		return "", datastore.ErrInvalidArgs.WithMessage("collection key or value is(are) missing")
 	}
 	if len(args) > 1 {
-		return "", fmt.Errorf("more params are passed than expected")
+		return "", datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
 	}
 
 	// extract the key from args
 	key, ok := args[0].(string)
 	if !ok {
-		return "", fmt.Errorf("invalid key is passed (not a string)")
+		return "", datastore.ErrInvalidArgs.WithMessage("invalid key is passed (not a string)")
+	}
+
+	// get the data
+	data, err := redigo.Bytes(rs.rejsonHandler.JSONGet(key, "."))
+	if err != nil {
+		return nil, WrapError(err, "error getting the data")
 	}
 
 	// return the data
-	return redigo.Bytes(rs.rejsonHandler.JSONGet(key, "."))
+	return data, nil
 }
 
 // gets all the keys from cache
 func (rs *redisStore) GetKeys(ctx context.Context, pattern string) ([]string, error) {
+
+	// check if the client is closed
+	if rs.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
+
 	if pattern == "" {
 		var keys []string
 		var cursor uint64 = 0
@@ -188,7 +450,7 @@ func (rs *redisStore) GetKeys(ctx context.Context, pattern string) ([]string, er
 			result, cursor, err = rs.client.Scan(ctx, cursor, "*", 10).Result()
 			if err != nil {
 				fmt.Println("error scanning keys:", err)
-				return nil, err
+				return nil, WrapError(err, "error scanning keys")
 			}
 			keys = append(keys, result...)
 			if cursor == 0 {
@@ -197,26 +459,112 @@ func (rs *redisStore) GetKeys(ctx context.Context, pattern string) ([]string, er
 		}
 		return keys, nil
 	}
-	return rs.client.Keys(ctx, pattern).Result()
+	keys, err := rs.client.Keys(ctx, pattern).Result()
+	if err != nil {
+		return nil, WrapError(err, "error getting keys")
+	}
+	return keys, nil
 }
 
 // deletes the data from cache
 func (rs *redisStore) DeleteData(ctx context.Context, args ...any) (any, error) {
 
+	// check if the client is closed
+	if rs.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
+
 	// validate the passed args
 	if len(args) < 1 {
-		return "", fmt.Errorf("collection key or value is(are) missing")
+		return "", datastore.ErrInvalidArgs.WithMessage("collection key or value is(are) missing")
 	}
 	if len(args) > 1 {
-		return "", fmt.Errorf("more params are passed than expected")
+		return "", datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
 	}
 
 	// extract the key from args
 	key, ok := args[0].(string)
 	if !ok {
-		return "", fmt.Errorf("invalid key is passed (not a string)")
+		return "", datastore.ErrInvalidArgs.WithMessage("invalid key is passed (not a string)")
+	}
+
+	// delete the data
+	res, err := rs.rejsonHandler.JSONDel(key, ".")
+	if err != nil {
+		return nil, WrapError(err, "error deleting the data")
 	}
 
 	// return the response
-	return rs.rejsonHandler.JSONDel(key, ".")
+	return res, nil
+}
+
+// sets the time to live of the key, false is returned when the key does not exist |
+// a non positive expiration deletes the key
+func (rs *redisStore) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+
+	// check if the client is closed
+	if rs.closed.Load() {
+		return false, datastore.ErrClosed
+	}
+
+	// set the expiry
+	ok, err := rs.client.PExpire(ctx, key, expiration).Result()
+	if err != nil {
+		return false, WrapError(err, "error setting the expiry")
+	}
+	return ok, nil
+}
+
+// returns the time to live of the key, cache.NoExpiration when it never expires and datastore.ErrNotFound when it does not exist
+func (rs *redisStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	// check if the client is closed
+	if rs.closed.Load() {
+		return 0, datastore.ErrClosed
+	}
+
+	// get the ttl, go-redis returns -2 (no key) and -1 (no expiry) as they are
+	ttl, err := rs.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, WrapError(err, "error getting the ttl")
+	}
+	switch ttl {
+	case -2:
+		return 0, datastore.ErrNotFound.WithMessage("key " + key + " does not exist")
+	case -1:
+		return cache.NoExpiration, nil
+	}
+	return ttl, nil
+}
+
+// removes the expiry of the key, false is returned when the key does not exist or has no expiry
+func (rs *redisStore) Persist(ctx context.Context, key string) (bool, error) {
+
+	// check if the client is closed
+	if rs.closed.Load() {
+		return false, datastore.ErrClosed
+	}
+
+	// remove the expiry
+	ok, err := rs.client.Persist(ctx, key).Result()
+	if err != nil {
+		return false, WrapError(err, "error removing the expiry")
+	}
+	return ok, nil
+}
+
+// reports whether the key exists
+func (rs *redisStore) Exists(ctx context.Context, key string) (bool, error) {
+
+	// check if the client is closed
+	if rs.closed.Load() {
+		return false, datastore.ErrClosed
+	}
+
+	// count the key
+	count, err := rs.client.Exists(ctx, key).Result()
+	if err != nil {
+		return false, WrapError(err, "error checking the key")
+	}
+	return count == 1, nil
 }
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gnanasuryateja/golib/datastore"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+// ErrCacheMiss is returned by TypedCache.Get when the key does not exist, it also matches datastore.ErrNotFound
+var ErrCacheMiss = datastore.ErrNotFound.WithMessage("cache miss")
+
+// TypedCache stores values of type T in a Cache, encoded with a Codec
+type TypedCache[T any] struct {
+	cache Cache
+	codec Codec
+}
+
+// creates a new TypedCache over the cache, JSONCodec is used when codec is nil
+func NewTypedCache[T any](c Cache, codec Codec) *TypedCache[T] {
+	if codec == nil {
+		codec = JSONCodec
+	}
+	return &TypedCache[T]{
+		cache: c,
+		codec: codec,
+	}
+}
+
+// returns the underlying cache
+func (tc *TypedCache[T]) Cache() Cache {
+	return tc.cache
+}
+
+// gets the value of the key, ErrCacheMiss is returned when the key does not exist
+func (tc *TypedCache[T]) Get(ctx context.Context, key string) (T, error) {
+	var value T
+
+	// get the data
+	data, err := tc.cache.GetData(ctx, key)
+	if errors.Is(err, datastore.ErrNotFound) {
+		return value, ErrCacheMiss.WithMessage("key " + key + " not found in cache")
+	}
+	if err != nil {
+		return value, err
+	}
+
+	// the cache returns the json the value was stored as
+	var raw []byte
+	switch data := data.(type) {
+	case []byte:
+		raw = data
+	case string:
+		raw = []byte(data)
+	default:
+		return value, datastore.ErrInvalidArgs.WithMessage("cache returned an unexpected type for key " + key)
+	}
+
+	// the non json codecs are stored as a base64 json string
+	if !tc.codec.IsJSON() {
+		var encoded []byte
+		err = json.Unmarshal(raw, &encoded)
+		if err != nil {
+			return value, datastore.Wrap(datastore.ErrInvalidArgs, "error decoding the data of key "+key, err)
+		}
+		raw = encoded
+	}
+	err = tc.codec.Unmarshal(raw, &value)
+	if err != nil {
+		return value, datastore.Wrap(datastore.ErrInvalidArgs, "error decoding the data of key "+key, err)
+	}
+	return value, nil
+}
+
+// sets the value of the key, WithExpiration sets the time to live of the key
+func (tc *TypedCache[T]) Set(ctx context.Context, key string, value T, options ...Option) error {
+
+	// encode the value
+	data, err := tc.codec.Marshal(value)
+	if err != nil {
+		return datastore.Wrap(datastore.ErrInvalidArgs, "error encoding the data of key "+key, err)
+	}
+	var stored any = data
+	if tc.codec.IsJSON() {
+		stored = json.RawMessage(data)
+	}
+
+	// add the data
+	args := []any{key, stored}
+	for _, option := range options {
+		args = append(args, option)
+	}
+	_, err = tc.cache.AddData(ctx, args...)
+	return err
+}
+
+// deletes the key, deleting a missing key is not an error
+func (tc *TypedCache[T]) Delete(ctx context.Context, key string) error {
+	_, err := tc.cache.DeleteData(ctx, key)
+	if errors.Is(err, datastore.ErrNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/gnanasuryateja/golib/datastore"
+	"github.com/gnanasuryateja/golib/datastore/cache"
+	"github.com/gnanasuryateja/golib/datastore/cache/memory"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+type user struct {
+	Name  string
+	Roles []string
+}
+
+func newTestCache(t *testing.T) cache.Cache {
+	t.Helper()
+	c, err := memory.NewMemoryStoreClient(context.Background(), memory.MemoryStoreConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close(context.Background()) })
+	return c
+}
+
+// returns the raw data stored for the key
+func rawData(t *testing.T, c cache.Cache, key string) []byte {
+	t.Helper()
+	data, err := c.GetData(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data.([]byte)
+}
+
+func TestTypedCacheMiss(t *testing.T) {
+	users := cache.NewTypedCache[user](newTestCache(t), nil)
+	_, err := users.Get(context.Background(), "missing")
+	if !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("Get() error = %v, want ErrCacheMiss", err)
+	}
+	if !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("Get() error = %v, want it to match datastore.ErrNotFound", err)
+	}
+}
+
+func TestTypedCacheRoundTrip(t *testing.T) {
+	want := user{Name: "ada", Roles: []string{"admin"}}
+	tests := []struct {
+		name  string
+		codec cache.Codec
+	}{
+		{"json", cache.JSONCodec},
+		{"gob", cache.GobCodec},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCache(t)
+			users := cache.NewTypedCache[user](c, tt.codec)
+			ctx := context.Background()
+			if err := users.Set(ctx, "user", want); err != nil {
+				t.Fatalf("Set() error: %v", err)
+			}
+			got, err := users.Get(ctx, "user")
+			if err != nil {
+				t.Fatalf("Get() error: %v", err)
+			}
+			if got.Name != want.Name || len(got.Roles) != 1 || got.Roles[0] != want.Roles[0] {
+				t.Errorf("Get() = %+v, want %+v", got, want)
+			}
+
+			// json values are stored as documents, the others as a base64 json string
+			raw := rawData(t, c, "user")
+			if tt.codec.IsJSON() {
+				var doc map[string]any
+				if err := json.Unmarshal(raw, &doc); err != nil || doc["Name"] != "ada" {
+					t.Errorf("stored data = %s, want a json document", raw)
+				}
+				return
+			}
+			var encoded string
+			if err := json.Unmarshal(raw, &encoded); err != nil {
+				t.Fatalf("stored data = %s, want a json string", raw)
+			}
+			if _, err := base64.StdEncoding.DecodeString(encoded); err != nil {
+				t.Errorf("stored string %q is not base64: %v", encoded, err)
+			}
+		})
+	}
+}
+
+func TestTypedCacheDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		codec  cache.Codec
+		stored any
+	}{
+		{"json of another type", cache.JSONCodec, "not a user"},
+		{"gob without the base64 envelope", cache.GobCodec, map[string]string{"Name": "ada"}},
+		{"gob envelope with invalid base64", cache.GobCodec, "not base64!"},
+		{"gob envelope with invalid gob", cache.GobCodec, []byte("not gob")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCache(t)
+			if _, err := c.AddData(context.Background(), "user", tt.stored); err != nil {
+				t.Fatal(err)
+			}
+			_, err := cache.NewTypedCache[user](c, tt.codec).Get(context.Background(), "user")
+			if !errors.Is(err, datastore.ErrInvalidArgs) {
+				t.Errorf("Get() error = %v, want ErrInvalidArgs", err)
+			}
+		})
+	}
+}
+
+func TestTypedCacheDelete(t *testing.T) {
+	c := newTestCache(t)
+	users := cache.NewTypedCache[user](c, nil)
+	ctx := context.Background()
+	if err := users.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Delete() of a missing key error = %v, want nil", err)
+	}
+	if err := users.Set(ctx, "user", user{Name: "ada"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.Delete(ctx, "user"); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if _, err := users.Get(ctx, "user"); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("Get() after Delete() error = %v, want ErrCacheMiss", err)
+	}
+}
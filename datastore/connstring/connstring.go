@@ -0,0 +1,221 @@
+package connstring
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/datastore"
+)
+
+const (
+	redacted_value   = "xxxxx"
+	scheme_separator = "://"
+	hosts_separator  = ","
+)
+
+var (
+	placeholderRegexp = regexp.MustCompile(`<[A-Za-z0-9_]+>`)
+
+	// option keys containing any of these are redacted
+	sensitiveOptionKeys = []string{"password", "secret", "token"}
+)
+
+type option struct {
+	key   string
+	value string
+}
+
+// Builder builds connection strings from a template with named placeholders like <username>, <password> and <hosts> |
+// the credentials are escaped for the userinfo part and are redacted when the Builder is printed
+type Builder struct {
+	template       string
+	values         map[string]string
+	username       string
+	password       string
+	hasCredentials bool
+	options        []option
+}
+
+// creates a new Builder for the template
+func New(template string) *Builder {
+	return &Builder{
+		template: template,
+		values:   map[string]string{},
+	}
+}
+
+// sets the value of the placeholder, the name can be passed with or without the angle brackets
+func (b *Builder) Set(name string, value string) *Builder {
+	b.values[placeholder(name)] = value
+	return b
+}
+
+// sets the <hosts> placeholder to the comma separated hosts
+func (b *Builder) Hosts(hosts ...string) *Builder {
+	return b.Set(constants.HOSTS, strings.Join(hosts, hosts_separator))
+}
+
+// sets the credentials, they fill the <username> and <password> placeholders |
+// when the template has no such placeholders they are added as the userinfo of the url
+func (b *Builder) Credentials(username string, password string) *Builder {
+	b.username = username
+	b.password = password
+	b.hasCredentials = true
+	return b
+}
+
+// appends the query option
+func (b *Builder) Option(key string, value string) *Builder {
+	b.options = append(b.options, option{key: key, value: value})
+	return b
+}
+
+// appends the query options in the sorted order of the keys
+func (b *Builder) Options(options map[string]string) *Builder {
+	for _, key := range sortedKeys(options) {
+		b.Option(key, options[key])
+	}
+	return b
+}
+
+// builds the connection string, all the placeholders of the template must be filled
+func (b *Builder) Build() (string, error) {
+	return b.build(false)
+}
+
+// builds the connection string with the password and the sensitive options redacted
+func (b *Builder) Redacted() string {
+	connString, _ := b.build(true)
+	return connString
+}
+
+// the Builder prints the redacted connection string
+func (b *Builder) String() string {
+	return b.Redacted()
+}
+
+func (b *Builder) build(redact bool) (string, error) {
+	username := escapeUsername(b.username)
+	password := escapePassword(b.password)
+	if redact && b.password != "" {
+		password = redacted_value
+	}
+
+	// fill the placeholders
+	var missing []string
+	connString := placeholderRegexp.ReplaceAllStringFunc(b.template, func(name string) string {
+		if b.hasCredentials && name == constants.USERNAME {
+			return username
+		}
+		if b.hasCredentials && name == constants.PASSWORD {
+			return password
+		}
+		if value, ok := b.values[name]; ok {
+			return value
+		}
+		missing = append(missing, name)
+		return name
+	})
+	if len(missing) > 0 && !redact {
+		return "", datastore.ErrInvalidArgs.WithMessagef("connection string placeholders %v are not filled", missing)
+	}
+
+	// add the credentials as the userinfo when the template has no placeholders for them
+	templateHasCredentials := strings.Contains(b.template, constants.USERNAME) || strings.Contains(b.template, constants.PASSWORD)
+	if b.hasCredentials && !templateHasCredentials && b.username != "" {
+		if scheme, rest, found := strings.Cut(connString, scheme_separator); found {
+			userinfo := username
+			if b.password != "" {
+				userinfo += ":" + password
+			}
+			connString = scheme + scheme_separator + userinfo + "@" + rest
+		}
+	}
+
+	// append the options
+	for _, o := range b.options {
+		value := o.value
+		if redact && isSensitive(o.key) {
+			value = redacted_value
+		}
+		separator := "&"
+		if !strings.Contains(connString, "?") {
+			separator = "?"
+			if strings.Contains(connString, scheme_separator) && !strings.Contains(strings.SplitN(connString, scheme_separator, 2)[1], "/") {
+				separator = "/?"
+			}
+		}
+		connString += separator + url.QueryEscape(o.key) + "=" + url.QueryEscape(value)
+	}
+	return connString, nil
+}
+
+// joins the host and the port, ipv6 hosts are enclosed in brackets
+func HostPort(host string, port string) string {
+	return net.JoinHostPort(host, port)
+}
+
+// splits the comma separated hosts (e.g. b1:9092,b2:9092) and validates that every host has a port
+func SplitHosts(hosts ...string) ([]string, error) {
+	var splitHosts []string
+	for _, h := range hosts {
+		for _, host := range strings.Split(h, hosts_separator) {
+			host = strings.TrimSpace(host)
+			if host == "" {
+				continue
+			}
+			if _, _, err := net.SplitHostPort(host); err != nil {
+				return nil, datastore.Wrap(datastore.ErrInvalidArgs, fmt.Sprintf("invalid host %s", host), err)
+			}
+			splitHosts = append(splitHosts, host)
+		}
+	}
+	if len(splitHosts) == 0 {
+		return nil, datastore.ErrInvalidArgs.WithMessage("hosts cannot be empty")
+	}
+	return splitHosts, nil
+}
+
+// returns the name enclosed in angle brackets
+func placeholder(name string) string {
+	if strings.HasPrefix(name, "<") && strings.HasSuffix(name, ">") {
+		return name
+	}
+	return "<" + name + ">"
+}
+
+// escapes the username for the userinfo part of the url
+func escapeUsername(username string) string {
+	return url.User(username).String()
+}
+
+// escapes the password for the userinfo part of the url
+func escapePassword(password string) string {
+	return strings.TrimPrefix(url.UserPassword("", password).String(), ":")
+}
+
+// reports whether the option key is sensitive
+func isSensitive(key string) bool {
+	lowerKey := strings.ToLower(key)
+	for _, sensitiveKey := range sensitiveOptionKeys {
+		if strings.Contains(lowerKey, sensitiveKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the keys of the map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
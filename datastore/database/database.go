@@ -3,7 +3,7 @@ package database
 import "context"
 
 type Database interface {
-	CloseDB(ctx context.Context, cancel context.CancelFunc)
+	Close(ctx context.Context) error
 	HealthCheck(ctx context.Context) error
 	AddData(ctx context.Context, args ...any) (string, error)
 	AddMultipleData(ctx context.Context, args ...any) ([]string, error)
@@ -3,119 +3,215 @@ package mongodb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
+	"sync/atomic"
 
 	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/datastore"
+	"github.com/gnanasuryateja/golib/datastore/connstring"
 	"github.com/gnanasuryateja/golib/datastore/database"
+	"github.com/gnanasuryateja/golib/secrets"
+	"github.com/gnanasuryateja/golib/validation"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MongoStoreConfig struct {
-	Uri      string
-	DbName   string
-	Username string
-	Password string
+	Uri      string            `config:"uri,required" validate:"required"` // Uri is the template with the <username>, <password>, <hosts> and <database> placeholders |
+	DbName   string            `config:"db_name,required" validate:"required"`
+	Username string            `config:"username,required" validate:"required"`
+	Password string            `config:"password,required" validate:"required"`
+	Hosts    []string          `config:"hosts" validate:"dive,required"` // Hosts fill the <hosts> placeholder of the Uri |
+	Options  map[string]string `config:"-"`                              // Options are appended to the Uri as query options
+
+	// SecretResolver resolves the Username and Password references (env://, file://, base64://, ...) |
+	// they are resolved once when the client is created, rotated secrets need a new client |
+	// secrets.NewResolver() is used when nil
+	SecretResolver *secrets.Resolver `config:"-"`
 }
 
 // validates the input params
 func (msc MongoStoreConfig) validate() error {
-	if msc.Uri == "" || msc.DbName == "" || msc.Username == "" || msc.Password == "" {
-		return fmt.Errorf("MongoStoreConfig cannot have empty values")
+	err := validation.Validate(msc)
+	if err != nil {
+		return datastore.Wrap(datastore.ErrInvalidArgs, "invalid MongoStoreConfig", err)
 	}
 	return nil
 }
 
+// wraps the mongo driver errors into the datastore errors
+func wrapError(err error, message string) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return datastore.Wrap(datastore.ErrNotFound, message, err)
+	case mongo.IsDuplicateKeyError(err):
+		return datastore.Wrap(datastore.ErrConflict, message, err)
+	case mongo.IsTimeout(err) || errors.Is(err, context.DeadlineExceeded):
+		return datastore.Wrap(datastore.ErrTimeout, message, err)
+	case mongo.IsNetworkError(err) || errors.Is(err, mongo.ErrClientDisconnected):
+		return datastore.Wrap(datastore.ErrConnection, message, err)
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// returns the connection string builder for the config
+func (msc MongoStoreConfig) builder() *connstring.Builder {
+	builder := connstring.New(msc.Uri).
+		Credentials(msc.Username, msc.Password).
+		Set(constants.DATABASE, msc.DbName).
+		Options(msc.Options)
+	if len(msc.Hosts) > 0 {
+		builder.Hosts(msc.Hosts...)
+	}
+	return builder
+}
+
+// prints the config with the password redacted
+func (msc MongoStoreConfig) String() string {
+	return fmt.Sprintf("{Uri:%s DbName:%s Username:%s}", msc.builder().Redacted(), msc.DbName, msc.Username)
+}
+
 type mongoStore struct {
 	client   *mongo.Client
 	database *mongo.Database
+	closed   atomic.Bool
 }
 
 // creates a new mongoStore client
-func NewMongoStoreClient(ctx context.Context, mongoStoreConfig MongoStoreConfig) (database.Database, context.CancelFunc, error) {
+func NewMongoStoreClient(ctx context.Context, mongoStoreConfig MongoStoreConfig) (database.Database, error) {
 
 	// validate the mongoStoreConfig
 	err := mongoStoreConfig.validate()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	// update the uri with username and password
-	mongoStoreConfig.Uri = strings.Replace(mongoStoreConfig.Uri, constants.USERNAME, mongoStoreConfig.Username, 1)
-	mongoStoreConfig.Uri = strings.Replace(mongoStoreConfig.Uri, constants.PASSWORD, mongoStoreConfig.Password, 1)
+	// get the secret resolver
+	resolver := mongoStoreConfig.SecretResolver
+	if resolver == nil {
+		resolver = secrets.NewResolver()
+	}
 
-	// get the ctx and cancel
-	ctx, cancel := context.WithCancel(ctx)
+	// resolve the username and password, the driver has no credentials callback
+	// so rotated secrets are picked up when a new client is created
+	mongoStoreConfig.Username, err = resolver.ResolveString(ctx, mongoStoreConfig.Username)
+	if err != nil {
+		return nil, datastore.Wrap(datastore.ErrInvalidArgs, "failed to resolve the username", err)
+	}
+	mongoStoreConfig.Password, err = resolver.ResolveString(ctx, mongoStoreConfig.Password)
+	if err != nil {
+		return nil, datastore.Wrap(datastore.ErrInvalidArgs, "failed to resolve the password", err)
+	}
+
+	// build the uri with the escaped username and password
+	uri, err := mongoStoreConfig.builder().Build()
+	if err != nil {
+		return nil, err
+	}
 
 	// build the clientOptions
-	clientOptions := options.Client().ApplyURI(mongoStoreConfig.Uri)
+	clientOptions := options.Client().ApplyURI(uri)
 
 	// get the mongo client
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		return nil, cancel, err
+		return nil, wrapError(err, "error connecting to mongo")
 	}
 
 	// check the connection
 	err = client.Ping(ctx, nil)
 	if err != nil {
-		return nil, cancel, err
+		_ = client.Disconnect(ctx)
+		return nil, wrapError(err, "error pinging mongo")
 	}
 
 	// get the database
 	database := client.Database(mongoStoreConfig.DbName)
 
 	// return the mongoStore
-	return mongoStore{
+	return &mongoStore{
 		client:   client,
 		database: database,
-	}, cancel, nil
+	}, nil
 }
 
-func (db mongoStore) CloseDB(ctx context.Context, cancel context.CancelFunc) {
-
-	// CancelFunc to cancel to context
-	defer cancel()
+// MongoDatabase returns the underlying mongo database, e.g. to run commands the Database interface does not cover
+func (db *mongoStore) MongoDatabase() (*mongo.Database, error) {
 
-	// client provides a method to close
-	// a mongoDB connection.
-	defer func() {
+	// check if the client is closed
+	if db.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
+	return db.database, nil
+}
 
-		// client.Disconnect method also has deadline.
-		// returns error if any,
-		if err := db.client.Disconnect(ctx); err != nil {
-			fmt.Println("error disconnecting from db: ", err)
-			// panic(err)
+// GetMongoDatabase returns the mongo database of a database created by NewMongoStoreClient |
+// decorators (retry, circuitbreaker) are unwrapped through their Unwrap method
+func GetMongoDatabase(db database.Database) (*mongo.Database, error) {
+	for db != nil {
+		if store, ok := db.(interface{ MongoDatabase() (*mongo.Database, error) }); ok {
+			return store.MongoDatabase()
+		}
+		wrapper, ok := db.(interface{ Unwrap() database.Database })
+		if !ok {
+			break
 		}
-	}()
+		db = wrapper.Unwrap()
+	}
+	return nil, datastore.ErrInvalidArgs.WithMessage("database is not a mongo database")
+}
+
+// closes the connection to db, calling it more than once is a no-op
+func (db *mongoStore) Close(ctx context.Context) error {
+	if db.closed.Swap(true) {
+		return nil
+	}
+
+	// client.Disconnect waits for the in use connections until the ctx deadline
+	err := db.client.Disconnect(ctx)
+	if err != nil {
+		return wrapError(err, "error disconnecting from db")
+	}
+	return nil
 }
 
 // checks the connection to db and returns an error if any
-func (db mongoStore) HealthCheck(ctx context.Context) error {
+func (db *mongoStore) HealthCheck(ctx context.Context) error {
+
+	// check if the client is closed
+	if db.closed.Load() {
+		return datastore.ErrClosed
+	}
+
 	// check the connection
 	err := db.client.Ping(ctx, nil)
 	if err != nil {
-		return err
+		return wrapError(err, "HealthCheck failed for MongoDB")
 	}
 	return nil
 }
 
 // inserts data into the db
-func (db mongoStore) AddData(ctx context.Context, args ...any) (string, error) {
+func (db *mongoStore) AddData(ctx context.Context, args ...any) (string, error) {
+
+	// check if the client is closed
+	if db.closed.Load() {
+		return "", datastore.ErrClosed
+	}
 
 	// validate the passed args
 	if len(args) < 2 {
-		return "", fmt.Errorf("collection name or data is(are) missing")
+		return "", datastore.ErrInvalidArgs.WithMessage("collection name or data is(are) missing")
 	}
 	if len(args) > 2 {
-		return "", fmt.Errorf("more params are passed than expected")
+		return "", datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
 	}
 
 	// get the collection string from args
 	coll, ok := args[0].(string)
 	if !ok {
-		return "", fmt.Errorf("invalid collection name is passed")
+		return "", datastore.ErrInvalidArgs.WithMessage("invalid collection name is passed")
 	}
 
 	// get the mongo collection
@@ -127,7 +223,7 @@ func (db mongoStore) AddData(ctx context.Context, args ...any) (string, error) {
 	// add the data to the collection
 	insertOneResult, err := collection.InsertOne(ctx, data)
 	if err != nil {
-		return "", fmt.Errorf("error inserting the data %v", err)
+		return "", wrapError(err, "error inserting the data")
 	}
 
 	// return the inserted id
@@ -135,20 +231,25 @@ func (db mongoStore) AddData(ctx context.Context, args ...any) (string, error) {
 }
 
 // inserts mutiple data into the db
-func (db mongoStore) AddMultipleData(ctx context.Context, args ...any) ([]string, error) {
+func (db *mongoStore) AddMultipleData(ctx context.Context, args ...any) ([]string, error) {
+
+	// check if the client is closed
+	if db.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
 
 	// validate the passed args
 	if len(args) < 2 {
-		return nil, fmt.Errorf("collection name or data is(are) missing")
+		return nil, datastore.ErrInvalidArgs.WithMessage("collection name or data is(are) missing")
 	}
 	if len(args) > 2 {
-		return nil, fmt.Errorf("more params are passed than expected")
+		return nil, datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
 	}
 
 	// get the collection string from args
 	coll, ok := args[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid collection name is passed")
+		return nil, datastore.ErrInvalidArgs.WithMessage("invalid collection name is passed")
 	}
 
 	// get the mongo collection
@@ -160,20 +261,20 @@ func (db mongoStore) AddMultipleData(ctx context.Context, args ...any) ([]string
 	// marshal the data to bytes
 	dataBytes, err := json.Marshal(data)
 	if err != nil {
-		return nil, err
+		return nil, datastore.Wrap(datastore.ErrInvalidArgs, "error marshalling the data", err)
 	}
 
 	// unmarshal the bytes to a slice
 	var sliceData []any
 	err = json.Unmarshal(dataBytes, &sliceData)
 	if err != nil {
-		return nil, err
+		return nil, datastore.Wrap(datastore.ErrInvalidArgs, "data is not a slice", err)
 	}
 
 	// add the data to the collection
 	insertManyResult, err := collection.InsertMany(ctx, sliceData)
 	if err != nil {
-		return nil, fmt.Errorf("error inserting the data %v", err)
+		return nil, wrapError(err, "error inserting the data")
 	}
 
 	// get the inserted ids
@@ -187,20 +288,25 @@ func (db mongoStore) AddMultipleData(ctx context.Context, args ...any) ([]string
 }
 
 // gets the data from db
-func (db mongoStore) GetData(ctx context.Context, args ...any) (any, error) {
+func (db *mongoStore) GetData(ctx context.Context, args ...any) (any, error) {
+
+	// check if the client is closed
+	if db.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
 
 	// validate the passed args
 	if len(args) < 2 {
-		return nil, fmt.Errorf("collection name or filter is(are) missing")
+		return nil, datastore.ErrInvalidArgs.WithMessage("collection name or filter is(are) missing")
 	}
 	if len(args) > 2 {
-		return nil, fmt.Errorf("more params are passed than expected")
+		return nil, datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
 	}
 
 	// get the collection string from args
 	coll, ok := args[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid collection name is passed")
+		return nil, datastore.ErrInvalidArgs.WithMessage("invalid collection name is passed")
 	}
 
 	// get the mongo collection
@@ -213,7 +319,7 @@ func (db mongoStore) GetData(ctx context.Context, args ...any) (any, error) {
 	var data any
 	err := collection.FindOne(ctx, filter).Decode(&data)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, "error getting the data")
 	}
 
 	// return the data
@@ -221,20 +327,25 @@ func (db mongoStore) GetData(ctx context.Context, args ...any) (any, error) {
 }
 
 // gets multiple data from db
-func (db mongoStore) GetMultipleData(ctx context.Context, args ...any) ([]any, error) {
+func (db *mongoStore) GetMultipleData(ctx context.Context, args ...any) ([]any, error) {
+
+	// check if the client is closed
+	if db.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
 
 	// validate the passed args
 	if len(args) < 2 {
-		return nil, fmt.Errorf("collection name or filter is(are) missing")
+		return nil, datastore.ErrInvalidArgs.WithMessage("collection name or filter is(are) missing")
 	}
 	if len(args) > 2 {
-		return nil, fmt.Errorf("more params are passed than expected")
+		return nil, datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
 	}
 
 	// get the collection string from args
 	coll, ok := args[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid collection name is passed")
+		return nil, datastore.ErrInvalidArgs.WithMessage("invalid collection name is passed")
 	}
 
 	// get the mongo collection
@@ -246,13 +357,13 @@ func (db mongoStore) GetMultipleData(ctx context.Context, args ...any) ([]any, e
 	// get the data from collection
 	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, "error getting the data")
 	}
 	defer cursor.Close(ctx)
 
 	// check for cursor error
 	if cursor.Err() != nil {
-		return nil, err
+		return nil, wrapError(cursor.Err(), "error getting the data")
 	}
 
 	// get the data from cursor
@@ -261,7 +372,7 @@ func (db mongoStore) GetMultipleData(ctx context.Context, args ...any) ([]any, e
 		var data any
 		err = cursor.Decode(&data)
 		if err != nil {
-			return nil, err
+			return nil, wrapError(err, "error decoding the data")
 		}
 		result = append(result, data)
 	}
@@ -271,20 +382,25 @@ func (db mongoStore) GetMultipleData(ctx context.Context, args ...any) ([]any, e
 }
 
 // updates the data in db
-func (db mongoStore) UpdateData(ctx context.Context, args ...any) (any, error) {
+func (db *mongoStore) UpdateData(ctx context.Context, args ...any) (any, error) {
+
+	// check if the client is closed
+	if db.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
 
 	// validate the passed args
 	if len(args) < 3 {
-		return nil, fmt.Errorf("collection name, filter or updateData is(are) missing")
+		return nil, datastore.ErrInvalidArgs.WithMessage("collection name, filter or updateData is(are) missing")
 	}
 	if len(args) > 3 {
-		return nil, fmt.Errorf("more params are passed than expected")
+		return nil, datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
 	}
 
 	// get the collection string from args
 	coll, ok := args[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid collection name is passed")
+		return nil, datastore.ErrInvalidArgs.WithMessage("invalid collection name is passed")
 	}
 
 	// get the mongo collection
@@ -299,7 +415,7 @@ func (db mongoStore) UpdateData(ctx context.Context, args ...any) (any, error) {
 	// update the data in collection
 	updateResult, err := collection.UpdateOne(ctx, filter, updateData)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, "error updating the data")
 	}
 
 	// return the modified count
@@ -307,20 +423,25 @@ func (db mongoStore) UpdateData(ctx context.Context, args ...any) (any, error) {
 }
 
 // updates multiple data in db
-func (db mongoStore) UpdateMultipleData(ctx context.Context, args ...any) (any, error) {
+func (db *mongoStore) UpdateMultipleData(ctx context.Context, args ...any) (any, error) {
+
+	// check if the client is closed
+	if db.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
 
 	// validate the passed args
 	if len(args) < 3 {
-		return nil, fmt.Errorf("collection name, filter or updateData is(are) missing")
+		return nil, datastore.ErrInvalidArgs.WithMessage("collection name, filter or updateData is(are) missing")
 	}
 	if len(args) > 3 {
-		return nil, fmt.Errorf("more params are passed than expected")
+		return nil, datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
 	}
 
 	// get the collection string from args
 	coll, ok := args[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid collection name is passed")
+		return nil, datastore.ErrInvalidArgs.WithMessage("invalid collection name is passed")
 	}
 
 	// get the mongo collection
@@ -335,7 +456,7 @@ func (db mongoStore) UpdateMultipleData(ctx context.Context, args ...any) (any,
 	// update the data in collection
 	updateResult, err := collection.UpdateMany(ctx, filter, updateData)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, "error updating the data")
 	}
 
 	// return the modified count
@@ -343,20 +464,25 @@ func (db mongoStore) UpdateMultipleData(ctx context.Context, args ...any) (any,
 }
 
 // deletes the data from db
-func (db mongoStore) DeleteData(ctx context.Context, args ...any) (any, error) {
+func (db *mongoStore) DeleteData(ctx context.Context, args ...any) (any, error) {
+
+	// check if the client is closed
+	if db.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
 
 	// validate the passed args
 	if len(args) < 2 {
-		return nil, fmt.Errorf("collection name or filter is(are) missing")
+		return nil, datastore.ErrInvalidArgs.WithMessage("collection name or filter is(are) missing")
 	}
 	if len(args) > 2 {
-		return nil, fmt.Errorf("more params are passed than expected")
+		return nil, datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
 	}
 
 	// get the collection string from args
 	coll, ok := args[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid collection name is passed")
+		return nil, datastore.ErrInvalidArgs.WithMessage("invalid collection name is passed")
 	}
 
 	// get the mongo collection
@@ -368,27 +494,32 @@ func (db mongoStore) DeleteData(ctx context.Context, args ...any) (any, error) {
 	// delete the data from collection
 	deleteResult, err := collection.DeleteOne(ctx, filter)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, "error deleting the data")
 	}
 
 	// return the deleted count
 	return fmt.Sprintf("%v document(s) have been updated", deleteResult.DeletedCount), nil
 }
 
-func (db mongoStore) DeleteMultipleData(ctx context.Context, args ...any) (any, error) {
+func (db *mongoStore) DeleteMultipleData(ctx context.Context, args ...any) (any, error) {
+
+	// check if the client is closed
+	if db.closed.Load() {
+		return nil, datastore.ErrClosed
+	}
 
 	// validate the passed args
 	if len(args) < 2 {
-		return nil, fmt.Errorf("collection name or filter is(are) missing")
+		return nil, datastore.ErrInvalidArgs.WithMessage("collection name or filter is(are) missing")
 	}
 	if len(args) > 2 {
-		return nil, fmt.Errorf("more params are passed than expected")
+		return nil, datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
 	}
 
 	// get the collection string from args
 	coll, ok := args[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid collection name is passed")
+		return nil, datastore.ErrInvalidArgs.WithMessage("invalid collection name is passed")
 	}
 
 	// get the mongo collection
@@ -400,7 +531,7 @@ func (db mongoStore) DeleteMultipleData(ctx context.Context, args ...any) (any,
 	// delete the data from collection
 	deleteResult, err := collection.DeleteMany(ctx, filter)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, "error deleting the data")
 	}
 
 	// return the deleted count
@@ -0,0 +1,105 @@
+package decorator
+
+import (
+	"context"
+	"time"
+
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/datastore"
+	"github.com/gnanasuryateja/golib/datastore/cache"
+)
+
+type decoratedCache struct {
+	cache       cache.Cache
+	interceptor Interceptor
+}
+
+// wraps the cache so that every operation but Close goes through the interceptor
+func NewCache(c cache.Cache, interceptor Interceptor) cache.Cache {
+	return &decoratedCache{
+		cache:       c,
+		interceptor: interceptor,
+	}
+}
+
+// returns the wrapped cache, e.g. to reach the redis client with redis.GetRedisClient
+func (dc *decoratedCache) Unwrap() cache.Cache {
+	return dc.cache
+}
+
+func (dc *decoratedCache) Close(ctx context.Context) error {
+	return dc.cache.Close(ctx)
+}
+
+func (dc *decoratedCache) HealthCheck(ctx context.Context) error {
+	return dc.interceptor(ctx, Operation{Name: "HealthCheck", Kind: constants.OPERATION_KIND_HEALTH_CHECK}, dc.cache.HealthCheck)
+}
+
+func (dc *decoratedCache) AddData(ctx context.Context, args ...any) (string, error) {
+	return intercept(ctx, dc.interceptor, "AddData", constants.OPERATION_KIND_WRITE, func(ctx context.Context) (string, error) {
+		return dc.cache.AddData(ctx, args...)
+	})
+}
+
+func (dc *decoratedCache) GetData(ctx context.Context, args ...any) (any, error) {
+	return intercept(ctx, dc.interceptor, "GetData", constants.OPERATION_KIND_READ, func(ctx context.Context) (any, error) {
+		return dc.cache.GetData(ctx, args...)
+	})
+}
+
+func (dc *decoratedCache) GetKeys(ctx context.Context, pattern string) ([]string, error) {
+	return intercept(ctx, dc.interceptor, "GetKeys", constants.OPERATION_KIND_READ, func(ctx context.Context) ([]string, error) {
+		return dc.cache.GetKeys(ctx, pattern)
+	})
+}
+
+func (dc *decoratedCache) DeleteData(ctx context.Context, args ...any) (any, error) {
+	return intercept(ctx, dc.interceptor, "DeleteData", constants.OPERATION_KIND_WRITE, func(ctx context.Context) (any, error) {
+		return dc.cache.DeleteData(ctx, args...)
+	})
+}
+
+func (dc *decoratedCache) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	return intercept(ctx, dc.interceptor, "Expire", constants.OPERATION_KIND_WRITE, func(ctx context.Context) (bool, error) {
+		return dc.cache.Expire(ctx, key, expiration)
+	})
+}
+
+func (dc *decoratedCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return intercept(ctx, dc.interceptor, "TTL", constants.OPERATION_KIND_READ, func(ctx context.Context) (time.Duration, error) {
+		return dc.cache.TTL(ctx, key)
+	})
+}
+
+func (dc *decoratedCache) Persist(ctx context.Context, key string) (bool, error) {
+	return intercept(ctx, dc.interceptor, "Persist", constants.OPERATION_KIND_WRITE, func(ctx context.Context) (bool, error) {
+		return dc.cache.Persist(ctx, key)
+	})
+}
+
+func (dc *decoratedCache) Exists(ctx context.Context, key string) (bool, error) {
+	return intercept(ctx, dc.interceptor, "Exists", constants.OPERATION_KIND_READ, func(ctx context.Context) (bool, error) {
+		return dc.cache.Exists(ctx, key)
+	})
+}
+
+// dataWithTTL is the result of GetDataWithTTL
+type dataWithTTL struct {
+	data []byte
+	ttl  time.Duration
+}
+
+// gets the data with its ttl through the interceptor when the wrapped cache supports it, see redis.GetDataWithTTL
+func (dc *decoratedCache) GetDataWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	store, ok := dc.cache.(interface {
+		GetDataWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error)
+	})
+	if !ok {
+		return nil, 0, datastore.ErrInvalidArgs.WithMessage("cache does not support GetDataWithTTL")
+	}
+	result, err := intercept(ctx, dc.interceptor, "GetDataWithTTL", constants.OPERATION_KIND_READ, func(ctx context.Context) (dataWithTTL, error) {
+		data, ttl, err := store.GetDataWithTTL(ctx, key)
+		return dataWithTTL{data: data, ttl: ttl}, err
+	})
+	return result.data, result.ttl, err
+}
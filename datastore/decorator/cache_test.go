@@ -0,0 +1,44 @@
+package decorator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gnanasuryateja/golib/datastore"
+	"github.com/gnanasuryateja/golib/datastore/cache"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+// ttlCache supports GetDataWithTTL, the other methods of cache.Cache are not called
+type ttlCache struct {
+	cache.Cache
+}
+
+func (ttlCache) GetDataWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	return []byte(`"value"`), time.Second, nil
+}
+
+func TestGetDataWithTTL(t *testing.T) {
+	var operations []Operation
+	interceptor := func(ctx context.Context, operation Operation, fn func(ctx context.Context) error) error {
+		operations = append(operations, operation)
+		return fn(ctx)
+	}
+
+	decorated := NewCache(ttlCache{}, interceptor).(*decoratedCache)
+	data, ttl, err := decorated.GetDataWithTTL(context.Background(), "key")
+	if err != nil || string(data) != `"value"` || ttl != time.Second {
+		t.Fatalf("GetDataWithTTL() = %s, %v, %v, want \"value\", 1s", data, ttl, err)
+	}
+	if len(operations) != 1 || operations[0].Name != "GetDataWithTTL" {
+		t.Fatalf("intercepted operations = %v, want GetDataWithTTL", operations)
+	}
+
+	// the wrapped cache does not support it
+	unsupported := NewCache(struct{ cache.Cache }{}, interceptor).(*decoratedCache)
+	_, _, err = unsupported.GetDataWithTTL(context.Background(), "key")
+	if !errors.Is(err, datastore.ErrInvalidArgs) {
+		t.Fatalf("GetDataWithTTL() of an unsupported cache error = %v, want ErrInvalidArgs", err)
+	}
+}
@@ -0,0 +1,82 @@
+package decorator
+
+import (
+	"context"
+
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/datastore/database"
+)
+
+type decoratedDatabase struct {
+	db          database.Database
+	interceptor Interceptor
+}
+
+// wraps the database so that every operation but Close goes through the interceptor
+func NewDatabase(db database.Database, interceptor Interceptor) database.Database {
+	return &decoratedDatabase{
+		db:          db,
+		interceptor: interceptor,
+	}
+}
+
+// returns the wrapped database, e.g. to reach the mongo database with mongodb.GetMongoDatabase
+func (dd *decoratedDatabase) Unwrap() database.Database {
+	return dd.db
+}
+
+func (dd *decoratedDatabase) Close(ctx context.Context) error {
+	return dd.db.Close(ctx)
+}
+
+func (dd *decoratedDatabase) HealthCheck(ctx context.Context) error {
+	return dd.interceptor(ctx, Operation{Name: "HealthCheck", Kind: constants.OPERATION_KIND_HEALTH_CHECK}, dd.db.HealthCheck)
+}
+
+func (dd *decoratedDatabase) AddData(ctx context.Context, args ...any) (string, error) {
+	return intercept(ctx, dd.interceptor, "AddData", constants.OPERATION_KIND_WRITE, func(ctx context.Context) (string, error) {
+		return dd.db.AddData(ctx, args...)
+	})
+}
+
+func (dd *decoratedDatabase) AddMultipleData(ctx context.Context, args ...any) ([]string, error) {
+	return intercept(ctx, dd.interceptor, "AddMultipleData", constants.OPERATION_KIND_WRITE, func(ctx context.Context) ([]string, error) {
+		return dd.db.AddMultipleData(ctx, args...)
+	})
+}
+
+func (dd *decoratedDatabase) GetData(ctx context.Context, args ...any) (any, error) {
+	return intercept(ctx, dd.interceptor, "GetData", constants.OPERATION_KIND_READ, func(ctx context.Context) (any, error) {
+		return dd.db.GetData(ctx, args...)
+	})
+}
+
+func (dd *decoratedDatabase) GetMultipleData(ctx context.Context, args ...any) ([]any, error) {
+	return intercept(ctx, dd.interceptor, "GetMultipleData", constants.OPERATION_KIND_READ, func(ctx context.Context) ([]any, error) {
+		return dd.db.GetMultipleData(ctx, args...)
+	})
+}
+
+func (dd *decoratedDatabase) UpdateData(ctx context.Context, args ...any) (any, error) {
+	return intercept(ctx, dd.interceptor, "UpdateData", constants.OPERATION_KIND_WRITE, func(ctx context.Context) (any, error) {
+		return dd.db.UpdateData(ctx, args...)
+	})
+}
+
+func (dd *decoratedDatabase) UpdateMultipleData(ctx context.Context, args ...any) (any, error) {
+	return intercept(ctx, dd.interceptor, "UpdateMultipleData", constants.OPERATION_KIND_WRITE, func(ctx context.Context) (any, error) {
+		return dd.db.UpdateMultipleData(ctx, args...)
+	})
+}
+
+func (dd *decoratedDatabase) DeleteData(ctx context.Context, args ...any) (any, error) {
+	return intercept(ctx, dd.interceptor, "DeleteData", constants.OPERATION_KIND_WRITE, func(ctx context.Context) (any, error) {
+		return dd.db.DeleteData(ctx, args...)
+	})
+}
+
+func (dd *decoratedDatabase) DeleteMultipleData(ctx context.Context, args ...any) (any, error) {
+	return intercept(ctx, dd.interceptor, "DeleteMultipleData", constants.OPERATION_KIND_WRITE, func(ctx context.Context) (any, error) {
+		return dd.db.DeleteMultipleData(ctx, args...)
+	})
+}
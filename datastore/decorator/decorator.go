@@ -0,0 +1,30 @@
+package decorator
+
+import (
+	"context"
+)
+
+// Operation describes the datastore operation passed to an Interceptor
+type Operation struct {
+	Name string // Name is the method called on the client, e.g. AddData |
+	Kind string // Kind is one of constants.OPERATION_KIND_*, writes may have landed even when they fail
+}
+
+// Interceptor runs fn, the call of the operation on the wrapped client, e.g. with retries or through a circuit breaker |
+// the results of the operation are captured by fn, the interceptor only sees its error
+type Interceptor func(ctx context.Context, operation Operation, fn func(ctx context.Context) error) error
+
+// runs the operation through the interceptor and returns its value
+func intercept[T any](ctx context.Context, interceptor Interceptor, name string, kind string, fn func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	err := interceptor(ctx, Operation{Name: name, Kind: kind}, func(ctx context.Context) error {
+		var err error
+		value, err = fn(ctx)
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return value, nil
+}
@@ -0,0 +1,46 @@
+package decorator
+
+import (
+	"context"
+
+	"github.com/gnanasuryateja/golib/constants"
+	messagingqueue "github.com/gnanasuryateja/golib/datastore/messaging_queue"
+)
+
+type decoratedMessageQueue struct {
+	messageQueue messagingqueue.MessageQueue
+	interceptor  Interceptor
+}
+
+// wraps the message queue so that every operation but Close goes through the interceptor
+func NewMessageQueue(messageQueue messagingqueue.MessageQueue, interceptor Interceptor) messagingqueue.MessageQueue {
+	return &decoratedMessageQueue{
+		messageQueue: messageQueue,
+		interceptor:  interceptor,
+	}
+}
+
+// returns the wrapped message queue
+func (dmq *decoratedMessageQueue) Unwrap() messagingqueue.MessageQueue {
+	return dmq.messageQueue
+}
+
+func (dmq *decoratedMessageQueue) Close(ctx context.Context) error {
+	return dmq.messageQueue.Close(ctx)
+}
+
+func (dmq *decoratedMessageQueue) HealthCheck(ctx context.Context) error {
+	return dmq.interceptor(ctx, Operation{Name: "HealthCheck", Kind: constants.OPERATION_KIND_HEALTH_CHECK}, dmq.messageQueue.HealthCheck)
+}
+
+func (dmq *decoratedMessageQueue) ProduceMessage(ctx context.Context, args ...any) error {
+	return dmq.interceptor(ctx, Operation{Name: "ProduceMessage", Kind: constants.OPERATION_KIND_WRITE}, func(ctx context.Context) error {
+		return dmq.messageQueue.ProduceMessage(ctx, args...)
+	})
+}
+
+func (dmq *decoratedMessageQueue) ConsumeMessage(ctx context.Context, args ...any) (any, error) {
+	return intercept(ctx, dmq.interceptor, "ConsumeMessage", constants.OPERATION_KIND_READ, func(ctx context.Context) (any, error) {
+		return dmq.messageQueue.ConsumeMessage(ctx, args...)
+	})
+}
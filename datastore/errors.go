@@ -0,0 +1,22 @@
+package datastore
+
+import (
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+// sentinel errors shared by all the datastore implementations |
+// implementations wrap their driver errors into these so that callers
+// can use errors.Is(err, datastore.ErrNotFound) irrespective of the backend
+var (
+	ErrNotFound    = errors.NotFound("data not found")
+	ErrInvalidArgs = errors.InvalidArgument("invalid arguments")
+	ErrConnection  = errors.Unavailable("connection failure")
+	ErrTimeout     = errors.DeadlineExceeded("operation timed out")
+	ErrConflict    = errors.Conflict("data conflict")
+	ErrClosed      = errors.FailedPrecondition("datastore client is closed")
+)
+
+// returns a copy of the sentinel error with the message and the cause set
+func Wrap(sentinel *errors.Error, message string, cause error) error {
+	return sentinel.WithMessage(message).Wrap(cause)
+}
@@ -2,23 +2,48 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"sync"
 
 	"github.com/IBM/sarama"
 
+	"github.com/gnanasuryateja/golib/datastore"
+	"github.com/gnanasuryateja/golib/datastore/connstring"
 	messagingqueue "github.com/gnanasuryateja/golib/datastore/messaging_queue"
 )
 
+// wraps the sarama errors into the datastore errors
+func wrapError(err error, message string) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, sarama.ErrUnknownTopicOrPartition):
+		return datastore.Wrap(datastore.ErrNotFound, message, err)
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, sarama.ErrRequestTimedOut) || (errors.As(err, &netErr) && netErr.Timeout()):
+		return datastore.Wrap(datastore.ErrTimeout, message, err)
+	case errors.Is(err, sarama.ErrOutOfBrokers) || errors.Is(err, sarama.ErrClosedClient) || errors.Is(err, sarama.ErrNotConnected) ||
+		errors.Is(err, sarama.ErrBrokerNotAvailable) || errors.Is(err, sarama.ErrLeaderNotAvailable) || errors.As(err, &netErr):
+		return datastore.Wrap(datastore.ErrConnection, message, err)
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
 type kafkaStore struct {
 	client  sarama.Client
 	lock    sync.Mutex
 	brokers []string
+	closed  bool
 }
 
 // creates a new kafka client
 func NewKafkaStoreClient(ctx context.Context, brokers []string) (messagingqueue.MessageQueue, error) {
-	return kafkaStore{
+	// split and validate the brokers, comma separated lists (e.g. from env vars) are accepted
+	brokers, err := connstring.SplitHosts(brokers...)
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaStore{
 		brokers: brokers,
 	}, nil
 }
@@ -28,6 +53,11 @@ func (k *kafkaStore) GetKafkaClient(brokers []string) (sarama.Client, error) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
 
+	// check if the store is closed, a closed store must not create new clients
+	if k.closed {
+		return nil, datastore.ErrClosed
+	}
+
 	// check if client already exists and is healthy
 	if k.client != nil && !k.client.Closed() {
 		fmt.Println("Using existing Kafka client")
@@ -41,15 +71,32 @@ func (k *kafkaStore) GetKafkaClient(brokers []string) (sarama.Client, error) {
 
 	client, err := sarama.NewClient(brokers, config)
 	if err != nil {
-		return nil, fmt.Errorf("unable to connect to Kafka broker: %v", err)
+		return nil, wrapError(err, "unable to connect to Kafka broker")
 	}
 
 	k.client = client
 	return k.client, nil
 }
 
+// closes the kafka client, calling it more than once is a no-op
+func (k *kafkaStore) Close(ctx context.Context) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.closed = true
+
+	// nothing to close when the client was never created
+	if k.client == nil || k.client.Closed() {
+		return nil
+	}
+	err := k.client.Close()
+	if err != nil && !errors.Is(err, sarama.ErrClosedClient) {
+		return wrapError(err, "error closing the Kafka client")
+	}
+	return nil
+}
+
 // checks the connection to kafka and return error if any
-func (k kafkaStore) HealthCheck(ctx context.Context) error {
+func (k *kafkaStore) HealthCheck(ctx context.Context) error {
 
 	// get the kafka client
 	client, err := k.GetKafkaClient(k.brokers)
@@ -60,13 +107,13 @@ func (k kafkaStore) HealthCheck(ctx context.Context) error {
 	// refresh metadata to ensure Kafka is reachable
 	err = client.RefreshMetadata()
 	if err != nil {
-		return fmt.Errorf("failed to refresh Kafka metadata: %v", err)
+		return wrapError(err, "failed to refresh Kafka metadata")
 	}
 
 	// check if we can retrieve broker information
 	brokersList := client.Brokers()
 	if len(brokersList) == 0 {
-		return fmt.Errorf("no brokers available")
+		return datastore.ErrConnection.WithMessage("no brokers available")
 	}
 
 	fmt.Println("Kafka is healthy, brokers:", brokersList)
@@ -74,7 +121,26 @@ func (k kafkaStore) HealthCheck(ctx context.Context) error {
 }
 
 // sends a message to a topic
-func (k kafkaStore) ProduceMessage(ctx context.Context, args ...any) error {
+func (k *kafkaStore) ProduceMessage(ctx context.Context, args ...any) error {
+
+	// validate the passed args
+	if len(args) < 2 {
+		return datastore.ErrInvalidArgs.WithMessage("topic or message is(are) missing")
+	}
+	if len(args) > 2 {
+		return datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
+	}
+
+	// get the topic and message
+	topic, ok := args[0].(string)
+	if !ok {
+		return datastore.ErrInvalidArgs.WithMessage("invalid topic is passed (not a string)")
+	}
+	message, ok := args[1].(string)
+	if !ok {
+		return datastore.ErrInvalidArgs.WithMessage("invalid message is passed (not a string)")
+	}
+
 	// Get or create a Kafka client
 	client, err := k.GetKafkaClient(k.brokers)
 	if err != nil {
@@ -84,14 +150,10 @@ func (k kafkaStore) ProduceMessage(ctx context.Context, args ...any) error {
 	// Create a new Kafka sync producer using the existing client
 	producer, err := sarama.NewSyncProducerFromClient(client)
 	if err != nil {
-		return fmt.Errorf("failed to create Kafka producer: %v", err)
+		return wrapError(err, "failed to create Kafka producer")
 	}
 	defer producer.Close()
 
-	// get the topic and message
-	topic := args[0].(string)
-	message := args[1].(string)
-
 	// Prepare the message to send
 	msg := &sarama.ProducerMessage{
 		Topic: topic,
@@ -101,7 +163,7 @@ func (k kafkaStore) ProduceMessage(ctx context.Context, args ...any) error {
 	// Send the message
 	partition, offset, err := producer.SendMessage(msg)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %v", err)
+		return wrapError(err, "failed to send message")
 	}
 
 	fmt.Printf("Message sent to partition %d at offset %d\n", partition, offset)
@@ -135,21 +197,36 @@ func (consumer *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 }
 
 // receives a message from a topic
-func (k kafkaStore) ConsumeMessage(ctx context.Context, args ...any) (any, error) {
+func (k *kafkaStore) ConsumeMessage(ctx context.Context, args ...any) (any, error) {
+
+	// validate the passed args
+	if len(args) < 2 {
+		return nil, datastore.ErrInvalidArgs.WithMessage("topic or groupId is(are) missing")
+	}
+	if len(args) > 2 {
+		return nil, datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
+	}
+
+	// get the topic and groupId
+	topic, ok := args[0].(string)
+	if !ok {
+		return nil, datastore.ErrInvalidArgs.WithMessage("invalid topic is passed (not a string)")
+	}
+	groupId, ok := args[1].(string)
+	if !ok {
+		return nil, datastore.ErrInvalidArgs.WithMessage("invalid groupId is passed (not a string)")
+	}
+
 	// Get or create a Kafka client
 	client, err := k.GetKafkaClient(k.brokers)
 	if err != nil {
 		return nil, err
 	}
 
-	// get the topic and groupId
-	topic := args[0].(string)
-	groupId := args[1].(string)
-
 	// Create a new Kafka consumer group
 	consumerGroup, err := sarama.NewConsumerGroupFromClient(groupId, client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Kafka consumer group: %v", err)
+		return nil, wrapError(err, "failed to create Kafka consumer group")
 	}
 	defer consumerGroup.Close()
 
@@ -162,12 +239,12 @@ func (k kafkaStore) ConsumeMessage(ctx context.Context, args ...any) (any, error
 	for {
 		err := consumerGroup.Consume(ctx, []string{topic}, &consumer)
 		if err != nil {
-			return nil, fmt.Errorf("error while consuming messages: %v", err)
+			return nil, wrapError(err, "error while consuming messages")
 		}
 
 		// Check if the consumer is ready
 		if !<-consumer.ready {
-			return nil, fmt.Errorf("consumer failed to be ready")
+			return nil, datastore.ErrConnection.WithMessage("consumer failed to be ready")
 		}
 	}
 }
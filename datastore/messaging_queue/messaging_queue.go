@@ -3,6 +3,7 @@ package messagingqueue
 import "context"
 
 type MessageQueue interface {
+	Close(ctx context.Context) error
 	HealthCheck(ctx context.Context) error
 	ProduceMessage(ctx context.Context, args ...any) error
 	ConsumeMessage(ctx context.Context, args ...any) (any, error)
@@ -0,0 +1,232 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/gnanasuryateja/golib/datastore"
+	redisstore "github.com/gnanasuryateja/golib/datastore/cache/redis"
+	messagingqueue "github.com/gnanasuryateja/golib/datastore/messaging_queue"
+)
+
+const (
+	redis_unsubscribe_timeout = 5 * time.Second
+	redis_channel_size        = 100
+)
+
+// Pattern subscribes to the channels matching the glob pattern (PSUBSCRIBE) when passed to ConsumeMessage, alone or as a []Pattern
+type Pattern string
+
+// Message is a message received on a subscription
+type Message struct {
+	Channel string // Channel is the channel the message was published on |
+	Pattern string // Pattern is the matched pattern of a pattern subscription, empty otherwise |
+	Payload string // Payload is the published message
+}
+
+// Handler is called for every message received by ConsumeMessage, an error stops the subscription
+type Handler func(ctx context.Context, message Message) error
+
+type redisPubSubStore struct {
+	client *redis.Client
+	lock   sync.Mutex
+	done   chan struct{}
+	closed bool
+}
+
+// creates a new redis pub/sub client, the connection setup (auth, tls, db) is the one of NewRedisStoreClient
+func NewRedisPubSubClient(ctx context.Context, redisStoreConfig redisstore.RedisStoreConfig) (messagingqueue.MessageQueue, error) {
+
+	// create a new redis client
+	client, err := redisstore.NewRedisClient(ctx, redisStoreConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &redisPubSubStore{
+		client: client,
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// closes the redis client and stops the running subscriptions, calling it more than once is a no-op
+func (rps *redisPubSubStore) Close(ctx context.Context) error {
+	rps.lock.Lock()
+	defer rps.lock.Unlock()
+	if rps.closed {
+		return nil
+	}
+	rps.closed = true
+	close(rps.done)
+	err := rps.client.Close()
+	if err != nil {
+		return redisstore.WrapError(err, "error closing the redis client")
+	}
+	return nil
+}
+
+// reports whether the client is closed
+func (rps *redisPubSubStore) isClosed() bool {
+	rps.lock.Lock()
+	defer rps.lock.Unlock()
+	return rps.closed
+}
+
+// checks the connection to redis and return error if any
+func (rps *redisPubSubStore) HealthCheck(ctx context.Context) error {
+
+	// check if the client is closed
+	if rps.isClosed() {
+		return datastore.ErrClosed
+	}
+
+	err := rps.client.Ping(ctx).Err()
+	if err != nil {
+		return redisstore.WrapError(err, "error pinging redis")
+	}
+	return nil
+}
+
+// publishes a message (string or []byte) on a channel
+func (rps *redisPubSubStore) ProduceMessage(ctx context.Context, args ...any) error {
+
+	// check if the client is closed
+	if rps.isClosed() {
+		return datastore.ErrClosed
+	}
+
+	// validate the passed args
+	if len(args) < 2 {
+		return datastore.ErrInvalidArgs.WithMessage("channel or message is(are) missing")
+	}
+	if len(args) > 2 {
+		return datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
+	}
+
+	// get the channel and message
+	channel, ok := args[0].(string)
+	if !ok || channel == "" {
+		return datastore.ErrInvalidArgs.WithMessage("invalid channel is passed (not a string)")
+	}
+	switch args[1].(type) {
+	case string, []byte:
+	default:
+		return datastore.ErrInvalidArgs.WithMessage("invalid message is passed (not a string or []byte)")
+	}
+
+	// publish the message
+	err := rps.client.Publish(ctx, channel, args[1]).Err()
+	if err != nil {
+		return redisstore.WrapError(err, "error publishing the message")
+	}
+	return nil
+}
+
+// subscribes to the channels (a string, []string, Pattern or []Pattern) and delivers the messages to the Handler |
+// it blocks until ctx is done (the subscription is then removed and nil is returned), the handler fails or the client is closed |
+// the subscription is restored after a reconnect, the messages published meanwhile are lost (redis pub/sub is at most once)
+func (rps *redisPubSubStore) ConsumeMessage(ctx context.Context, args ...any) (any, error) {
+
+	// check if the client is closed
+	if rps.isClosed() {
+		return nil, datastore.ErrClosed
+	}
+
+	// validate the passed args
+	if len(args) < 2 {
+		return nil, datastore.ErrInvalidArgs.WithMessage("channel or handler is(are) missing")
+	}
+	if len(args) > 2 {
+		return nil, datastore.ErrInvalidArgs.WithMessage("more params are passed than expected")
+	}
+
+	// get the handler
+	var handler Handler
+	switch h := args[1].(type) {
+	case Handler:
+		handler = h
+	case func(ctx context.Context, message Message) error:
+		handler = h
+	}
+	if handler == nil {
+		return nil, datastore.ErrInvalidArgs.WithMessage("invalid handler is passed (not a Handler)")
+	}
+
+	// subscribe to the channels
+	var pubsub *redis.PubSub
+	switch subscription := args[0].(type) {
+	case string:
+		if subscription == "" {
+			return nil, datastore.ErrInvalidArgs.WithMessage("channel is empty")
+		}
+		pubsub = rps.client.Subscribe(ctx, subscription)
+	case []string:
+		if len(subscription) == 0 {
+			return nil, datastore.ErrInvalidArgs.WithMessage("channels are empty")
+		}
+		pubsub = rps.client.Subscribe(ctx, subscription...)
+	case Pattern:
+		if subscription == "" {
+			return nil, datastore.ErrInvalidArgs.WithMessage("pattern is empty")
+		}
+		pubsub = rps.client.PSubscribe(ctx, string(subscription))
+	case []Pattern:
+		patterns := make([]string, 0, len(subscription))
+		for _, pattern := range subscription {
+			if pattern == "" {
+				return nil, datastore.ErrInvalidArgs.WithMessage("pattern is empty")
+			}
+			patterns = append(patterns, string(pattern))
+		}
+		if len(patterns) == 0 {
+			return nil, datastore.ErrInvalidArgs.WithMessage("patterns are empty")
+		}
+		pubsub = rps.client.PSubscribe(ctx, patterns...)
+	default:
+		return nil, datastore.ErrInvalidArgs.WithMessage("invalid channel is passed (not a string, []string, Pattern or []Pattern)")
+	}
+	defer unsubscribe(pubsub)
+
+	// wait for the subscription to be confirmed
+	_, err := pubsub.Receive(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+		return nil, redisstore.WrapError(err, "error subscribing")
+	}
+
+	// the channel pings the connection and resubscribes after a reconnect
+	messages := pubsub.Channel(redis.WithChannelSize(redis_channel_size))
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-rps.done:
+			return nil, datastore.ErrClosed
+		case msg, ok := <-messages:
+			if !ok {
+				return nil, datastore.ErrClosed
+			}
+			err := handler(ctx, Message{
+				Channel: msg.Channel,
+				Pattern: msg.Pattern,
+				Payload: msg.Payload,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// removes the subscriptions before closing the connection
+func unsubscribe(pubsub *redis.PubSub) {
+	ctx, cancel := context.WithTimeout(context.Background(), redis_unsubscribe_timeout)
+	defer cancel()
+	_ = pubsub.Unsubscribe(ctx)
+	_ = pubsub.PUnsubscribe(ctx)
+	_ = pubsub.Close()
+}
@@ -0,0 +1,292 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gnanasuryateja/golib/datastore"
+	redisstore "github.com/gnanasuryateja/golib/datastore/cache/redis"
+	messagingqueue "github.com/gnanasuryateja/golib/datastore/messaging_queue"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+// fakeServer speaks enough of the redis protocol for the pub/sub client, PUBLISH delivers to the SUBSCRIBE connections
+type fakeServer struct {
+	listener    net.Listener
+	lock        sync.Mutex
+	subscribers map[string][]*bufio.Writer
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := &fakeServer{
+		listener:    listener,
+		subscribers: map[string][]*bufio.Writer{},
+	}
+	t.Cleanup(func() { listener.Close() })
+	go fs.serve()
+	return fs
+}
+
+func (fs *fakeServer) serve() {
+	for {
+		conn, err := fs.listener.Accept()
+		if err != nil {
+			return
+		}
+		go fs.handle(conn)
+	}
+}
+
+// reads the commands of the connection and answers them
+func (fs *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		fs.lock.Lock()
+		switch strings.ToUpper(args[0]) {
+		case "HELLO":
+			fmt.Fprint(writer, "-ERR unknown command\r\n")
+		case "PING":
+			fmt.Fprint(writer, "+PONG\r\n")
+		case "SUBSCRIBE", "UNSUBSCRIBE", "PSUBSCRIBE", "PUNSUBSCRIBE":
+			kind := strings.ToLower(args[0])
+			for i, channel := range args[1:] {
+				if kind == "subscribe" || kind == "psubscribe" {
+					fs.subscribers[channel] = append(fs.subscribers[channel], writer)
+				}
+				fmt.Fprintf(writer, "*3\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n:%d\r\n", len(kind), kind, len(channel), channel, i+1)
+			}
+		case "PUBLISH":
+			channel, payload := args[1], args[2]
+			for subscription, writers := range fs.subscribers {
+				for _, subscriber := range writers {
+					if subscription == channel {
+						fmt.Fprintf(subscriber, "*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(channel), channel, len(payload), payload)
+					} else if strings.HasSuffix(subscription, "*") && strings.HasPrefix(channel, strings.TrimSuffix(subscription, "*")) {
+						fmt.Fprintf(subscriber, "*4\r\n$8\r\npmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(subscription), subscription, len(channel), channel, len(payload), payload)
+					} else {
+						continue
+					}
+					subscriber.Flush()
+				}
+			}
+			fmt.Fprint(writer, ":1\r\n")
+		default:
+			fmt.Fprint(writer, "+OK\r\n")
+		}
+		writer.Flush()
+		fs.lock.Unlock()
+	}
+}
+
+// reads a command sent as an array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		line, err = reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func newTestClient(t *testing.T, addr string) messagingqueue.MessageQueue {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(addr)
+	client, err := NewRedisPubSubClient(context.Background(), redisstore.RedisStoreConfig{Addr: host, Port: port, Username: "user", Password: "password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close(context.Background()) })
+	return client
+}
+
+func TestConsumeMessageArgs(t *testing.T) {
+	client := newTestClient(t, newFakeServer(t).listener.Addr().String())
+	handler := func(ctx context.Context, message Message) error { return nil }
+	tests := []struct {
+		name string
+		args []any
+	}{
+		{"missing handler", []any{"channel"}},
+		{"too many args", []any{"channel", handler, "extra"}},
+		{"invalid handler", []any{"channel", func(message string) {}}},
+		{"nil handler", []any{"channel", Handler(nil)}},
+		{"empty channel", []any{"", handler}},
+		{"empty channels", []any{[]string{}, handler}},
+		{"empty pattern", []any{Pattern(""), handler}},
+		{"empty patterns", []any{[]Pattern{}, handler}},
+		{"empty pattern entry", []any{[]Pattern{"a.*", ""}, handler}},
+		{"invalid channel", []any{42, handler}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ConsumeMessage(context.Background(), tt.args...)
+			if !errors.Is(err, datastore.ErrInvalidArgs) {
+				t.Errorf("ConsumeMessage() error = %v, want ErrInvalidArgs", err)
+			}
+		})
+	}
+}
+
+func TestProduceMessageArgs(t *testing.T) {
+	client := newTestClient(t, newFakeServer(t).listener.Addr().String())
+	tests := []struct {
+		name string
+		args []any
+	}{
+		{"missing message", []any{"channel"}},
+		{"empty channel", []any{"", "message"}},
+		{"invalid message", []any{"channel", 42}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.ProduceMessage(context.Background(), tt.args...)
+			if !errors.Is(err, datastore.ErrInvalidArgs) {
+				t.Errorf("ProduceMessage() error = %v, want ErrInvalidArgs", err)
+			}
+		})
+	}
+}
+
+// consumes in a goroutine, the result of ConsumeMessage is sent on the returned channel
+func consume(ctx context.Context, client messagingqueue.MessageQueue, subscription any, handler any) chan error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.ConsumeMessage(ctx, subscription, handler)
+		done <- err
+	}()
+	return done
+}
+
+// publishes until the handler received a message, the subscription may not be confirmed yet
+func publishUntil(t *testing.T, client messagingqueue.MessageQueue, channel string, received chan Message) Message {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if err := client.ProduceMessage(context.Background(), channel, "payload"); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case message := <-received:
+			return message
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatal("no message was received")
+	return Message{}
+}
+
+func TestConsumeMessageHandlers(t *testing.T) {
+	client := newTestClient(t, newFakeServer(t).listener.Addr().String())
+	tests := []struct {
+		name         string
+		subscription any
+		channel      string
+		wantPattern  string
+		handler      func(received chan Message) any
+	}{
+		{"channel with a Handler", "orders", "orders", "", func(received chan Message) any {
+			return Handler(func(ctx context.Context, message Message) error { received <- message; return nil })
+		}},
+		{"channels with a func", []string{"users", "teams"}, "teams", "", func(received chan Message) any {
+			return func(ctx context.Context, message Message) error { received <- message; return nil }
+		}},
+		{"pattern", Pattern("events.*"), "events.created", "events.*", func(received chan Message) any {
+			return func(ctx context.Context, message Message) error { received <- message; return nil }
+		}},
+		{"patterns", []Pattern{"a.*", "b.*"}, "b.deleted", "b.*", func(received chan Message) any {
+			return func(ctx context.Context, message Message) error { received <- message; return nil }
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			received := make(chan Message, 100)
+			done := consume(ctx, client, tt.subscription, tt.handler(received))
+
+			message := publishUntil(t, client, tt.channel, received)
+			if message.Channel != tt.channel || message.Pattern != tt.wantPattern || message.Payload != "payload" {
+				t.Errorf("message = %+v, want channel %s and pattern %q", message, tt.channel, tt.wantPattern)
+			}
+
+			// ctx cancel removes the subscription and returns nil
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("ConsumeMessage() after the ctx cancel error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestConsumeMessageExits(t *testing.T) {
+	addr := newFakeServer(t).listener.Addr().String()
+
+	t.Run("handler error", func(t *testing.T) {
+		client := newTestClient(t, addr)
+		handlerErr := errors.Internal("handler failed")
+		received := make(chan Message, 100)
+		done := consume(context.Background(), client, "jobs", func(ctx context.Context, message Message) error {
+			received <- message
+			return handlerErr
+		})
+		publishUntil(t, client, "jobs", received)
+		if err := <-done; !errors.Is(err, handlerErr) {
+			t.Errorf("ConsumeMessage() error = %v, want the handler error", err)
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		client := newTestClient(t, addr)
+		received := make(chan Message, 100)
+		done := consume(context.Background(), client, "jobs", func(ctx context.Context, message Message) error {
+			received <- message
+			return nil
+		})
+		publishUntil(t, client, "jobs", received)
+		if err := client.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if err := <-done; !errors.Is(err, datastore.ErrClosed) {
+			t.Errorf("ConsumeMessage() after Close error = %v, want ErrClosed", err)
+		}
+		if _, err := client.ConsumeMessage(context.Background(), "jobs", Handler(func(ctx context.Context, message Message) error { return nil })); !errors.Is(err, datastore.ErrClosed) {
+			t.Errorf("ConsumeMessage() of a closed client error = %v, want ErrClosed", err)
+		}
+	})
+}
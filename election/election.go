@@ -0,0 +1,267 @@
+package election
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/errors"
+	"github.com/gnanasuryateja/golib/idgen"
+	"github.com/gnanasuryateja/golib/validation"
+)
+
+const (
+	default_lease_duration  = 15 * time.Second
+	default_release_timeout = 5 * time.Second
+	identity_divider        = "-"
+)
+
+// Backend stores the leases, NewRedisBackend and NewMongoBackend are provided
+type Backend interface {
+	// acquires the lease for the identity when it is free (or expired) or renews it when the identity holds it |
+	// true is returned when the identity holds the lease afterwards
+	AcquireOrRenew(ctx context.Context, name string, identity string, ttl time.Duration) (bool, error)
+	// frees the lease when the identity holds it
+	Release(ctx context.Context, name string, identity string) error
+	// returns the identity holding the lease, empty when there is none
+	Holder(ctx context.Context, name string) (string, error)
+}
+
+type Config struct {
+	Name             string                    `validate:"required"` // Name identifies the election, the candidates of the same election share it |
+	Identity         string                    // Identity identifies this candidate, the hostname with a random suffix by default |
+	Backend          Backend                   `validate:"required"` // Backend stores the lease |
+	LeaseDuration    time.Duration             `validate:"min=0"`    // LeaseDuration is the ttl of the lease, 15s by default |
+	RenewDeadline    time.Duration             `validate:"min=0"`    // RenewDeadline is how long the leader keeps leading without a renewal, shorter than LeaseDuration, 2/3 of it by default |
+	RetryInterval    time.Duration             `validate:"min=0"`    // RetryInterval is the period of the acquire and renew attempts, LeaseDuration/3 by default |
+	ReleaseTimeout   time.Duration             `validate:"min=0"`    // ReleaseTimeout bounds the release of the lease on step-down, 5s by default |
+	OnStartedLeading func(ctx context.Context) `validate:"required"` // OnStartedLeading runs the work of the leader, its ctx is cancelled when the leadership ends |
+	OnStoppedLeading func()                    // OnStoppedLeading is called after OnStartedLeading returned when the leadership ends |
+	OnNewLeader      func(identity string)     // OnNewLeader is called when the observed leader changes, identity is empty when there is none |
+	Clock            clock.Clock               `validate:"-"` // Clock drives the attempts, the real clock by default
+}
+
+// validates the input params, the durations are compared with the defaults filled in
+func (c Config) validate() error {
+	err := validation.Validate(c)
+	if err != nil {
+		return err
+	}
+
+	// the leader must step down before the lease expires so that two leaders never overlap
+	if c.RenewDeadline >= c.LeaseDuration {
+		return errors.InvalidArgument("RenewDeadline must be shorter than LeaseDuration")
+	}
+	if c.RetryInterval >= c.RenewDeadline {
+		return errors.InvalidArgument("RetryInterval must be shorter than RenewDeadline")
+	}
+	return nil
+}
+
+// returns the config with the defaults filled in
+func (c Config) withDefaults() Config {
+	if c.Identity == "" {
+		hostname, _ := os.Hostname()
+		c.Identity = hostname + identity_divider + idgen.NewULID().String()
+	}
+	if c.LeaseDuration == 0 {
+		c.LeaseDuration = default_lease_duration
+	}
+	if c.RenewDeadline == 0 {
+		c.RenewDeadline = c.LeaseDuration * 2 / 3
+	}
+	if c.RetryInterval == 0 {
+		c.RetryInterval = c.LeaseDuration / 3
+	}
+	if c.ReleaseTimeout == 0 {
+		c.ReleaseTimeout = default_release_timeout
+	}
+	c.Clock = clock.OrReal(c.Clock)
+	return c
+}
+
+// Elector takes part in a leader election and runs OnStartedLeading while it leads
+type Elector struct {
+	config  Config
+	leading atomic.Bool
+	lock    sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{}
+	leader  string
+}
+
+// creates a new Elector, call Run to take part in the election
+func NewElector(config Config) (*Elector, error) {
+
+	// validate the config
+	config = config.withDefaults()
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Elector{
+		config: config,
+	}, nil
+}
+
+// returns the identity of the candidate
+func (e *Elector) Identity() string {
+	return e.config.Identity
+}
+
+// reports whether the candidate currently leads
+func (e *Elector) IsLeader() bool {
+	return e.leading.Load()
+}
+
+// returns the identity of the current leader from the backend
+func (e *Elector) Leader(ctx context.Context) (string, error) {
+	return e.config.Backend.Holder(ctx, e.config.Name)
+}
+
+// takes part in the election until ctx is done or Close is called, then steps down |
+// the lease is acquired or renewed every RetryInterval, the leadership ends when the lease is taken over or
+// could not be renewed within RenewDeadline
+func (e *Elector) Run(ctx context.Context) error {
+	e.lock.Lock()
+	if e.done != nil {
+		e.lock.Unlock()
+		return errors.FailedPrecondition("elector " + e.config.Identity + " can only run once")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	e.lock.Unlock()
+	defer close(e.done)
+	defer cancel()
+
+	config := e.config
+	ticker := config.Clock.NewTicker(config.RetryInterval)
+	defer ticker.Stop()
+
+	var leader *leadership
+	var renewedAt time.Time
+	for {
+		// acquire or renew the lease, the renewal is bounded by the RenewDeadline
+		attemptedAt := config.Clock.Now()
+		held, err := e.acquireOrRenew(ctx, leader, renewedAt)
+		if ctx.Err() != nil {
+			return e.stepDown(ctx, leader, held || leader != nil)
+		}
+		switch {
+		case held:
+			renewedAt = attemptedAt
+			if leader == nil {
+				leader = e.startLeading(ctx)
+			}
+		case leader != nil && (err == nil || config.Clock.Since(renewedAt) >= config.RenewDeadline):
+			// the lease was taken over or could not be renewed before the RenewDeadline
+			e.stopLeading(leader)
+			leader = nil
+		}
+		if err == nil {
+			e.observeLeader(ctx, held)
+		}
+
+		// wait for the next attempt
+		select {
+		case <-ctx.Done():
+			return e.stepDown(ctx, leader, leader != nil)
+		case <-ticker.C():
+		}
+	}
+}
+
+// acquires or renews the lease, the renewal of the leader is cancelled when it would end after the RenewDeadline
+func (e *Elector) acquireOrRenew(ctx context.Context, leader *leadership, renewedAt time.Time) (bool, error) {
+	if leader != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.config.RenewDeadline-e.config.Clock.Since(renewedAt))
+		defer cancel()
+	}
+	return e.config.Backend.AcquireOrRenew(ctx, e.config.Name, e.config.Identity, e.config.LeaseDuration)
+}
+
+// stops the leader and releases the lease after the work stopped so that the next leader does not overlap |
+// the lease is released whenever it was held, also when ctx was done before the leader started
+func (e *Elector) stepDown(ctx context.Context, leader *leadership, held bool) error {
+	if leader != nil {
+		e.stopLeading(leader)
+	}
+	if !held {
+		return nil
+	}
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.config.ReleaseTimeout)
+	defer cancel()
+	return e.config.Backend.Release(releaseCtx, e.config.Name, e.config.Identity)
+}
+
+// stops the election and waits for the step-down until ctx is done, it can be registered with the lifecycle manager
+func (e *Elector) Close(ctx context.Context) error {
+	e.lock.Lock()
+	cancel, done := e.cancel, e.done
+	e.lock.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type leadership struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// runs OnStartedLeading with a ctx which is cancelled when the leadership ends
+func (e *Elector) startLeading(ctx context.Context) *leadership {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	leader := &leadership{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	e.leading.Store(true)
+	go func() {
+		defer close(leader.done)
+		e.config.OnStartedLeading(leaderCtx)
+	}()
+	return leader
+}
+
+// cancels the work of the leader and waits for it before calling OnStoppedLeading
+func (e *Elector) stopLeading(leader *leadership) {
+	leader.cancel()
+	<-leader.done
+	e.leading.Store(false)
+	if e.config.OnStoppedLeading != nil {
+		e.config.OnStoppedLeading()
+	}
+}
+
+// calls OnNewLeader when the observed leader changed
+func (e *Elector) observeLeader(ctx context.Context, held bool) {
+	if e.config.OnNewLeader == nil {
+		return
+	}
+	leader := e.config.Identity
+	if !held {
+		holder, err := e.config.Backend.Holder(ctx, e.config.Name)
+		if err != nil {
+			return
+		}
+		leader = holder
+	}
+	if leader != e.leader {
+		e.leader = leader
+		e.config.OnNewLeader(leader)
+	}
+}
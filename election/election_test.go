@@ -0,0 +1,152 @@
+package election
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gnanasuryateja/golib/clock"
+)
+
+// fakeBackend answers the attempts with acquire and records the releases
+type fakeBackend struct {
+	lock     sync.Mutex
+	acquire  func(ctx context.Context, attempt int) (bool, error)
+	attempts int
+	released chan struct{}
+}
+
+func newFakeBackend(acquire func(ctx context.Context, attempt int) (bool, error)) *fakeBackend {
+	return &fakeBackend{
+		acquire:  acquire,
+		released: make(chan struct{}, 1),
+	}
+}
+
+func (fb *fakeBackend) AcquireOrRenew(ctx context.Context, name string, identity string, ttl time.Duration) (bool, error) {
+	fb.lock.Lock()
+	fb.attempts++
+	attempt := fb.attempts
+	fb.lock.Unlock()
+	return fb.acquire(ctx, attempt)
+}
+
+func (fb *fakeBackend) Release(ctx context.Context, name string, identity string) error {
+	fb.released <- struct{}{}
+	return nil
+}
+
+func (fb *fakeBackend) Holder(ctx context.Context, name string) (string, error) {
+	return "", nil
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"defaults", Config{}, false},
+		{"renew deadline equal to the lease", Config{LeaseDuration: 9 * time.Second, RenewDeadline: 9 * time.Second}, true},
+		{"retry interval longer than the renew deadline", Config{RenewDeadline: 4 * time.Second, RetryInterval: 5 * time.Second}, true},
+		{"custom durations", Config{LeaseDuration: 9 * time.Second, RenewDeadline: 8 * time.Second, RetryInterval: time.Second}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Name = "test"
+			config.Backend = newFakeBackend(nil)
+			config.OnStartedLeading = func(ctx context.Context) {}
+			_, err := NewElector(config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewElector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunReleasesLeaseAcquiredBeforeCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	backend := newFakeBackend(func(_ context.Context, attempt int) (bool, error) {
+		cancel()
+		return true, nil
+	})
+	elector, err := NewElector(Config{
+		Name:             "test",
+		Backend:          backend,
+		OnStartedLeading: func(ctx context.Context) {},
+		Clock:            clock.NewFake(time.Unix(0, 0)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := elector.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	select {
+	case <-backend.released:
+	default:
+		t.Fatal("the lease acquired before the cancel was not released")
+	}
+}
+
+func TestRunStepsDownAfterRenewDeadline(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	attempts := make(chan int, 10)
+	backend := newFakeBackend(func(_ context.Context, attempt int) (bool, error) {
+		attempts <- attempt
+		if attempt == 1 {
+			return true, nil
+		}
+		return false, errors.New("connection refused")
+	})
+	stopped := make(chan struct{})
+	elector, err := NewElector(Config{
+		Name:             "test",
+		Backend:          backend,
+		LeaseDuration:    15 * time.Second,
+		RenewDeadline:    10 * time.Second,
+		RetryInterval:    5 * time.Second,
+		OnStartedLeading: func(ctx context.Context) { <-ctx.Done() },
+		OnStoppedLeading: func() { close(stopped) },
+		Clock:            fake,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go elector.Run(ctx)
+
+	<-attempts
+	for i := 0; i < 2; i++ {
+		fake.Advance(5 * time.Second)
+		<-attempts
+		if i == 0 && !waitLeading(elector) {
+			t.Fatal("the leader stepped down before the renew deadline")
+		}
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("the leader did not step down after the renew deadline")
+	}
+	if elector.IsLeader() {
+		t.Error("IsLeader() = true after the renew deadline")
+	}
+}
+
+// waits briefly for the elector to lead, the leadership starts after the attempt returned
+func waitLeading(e *Elector) bool {
+	for i := 0; i < 100; i++ {
+		if e.IsLeader() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package election
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/gnanasuryateja/golib/datastore"
+	"github.com/gnanasuryateja/golib/datastore/database"
+	"github.com/gnanasuryateja/golib/datastore/database/mongodb"
+)
+
+const (
+	default_mongo_collection = "leases"
+	lease_holder_field       = "holder"
+	lease_expires_at_field   = "expires_at"
+)
+
+type lease struct {
+	Name      string    `bson:"_id"`
+	Holder    string    `bson:"holder"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+type mongoBackend struct {
+	collection *mongo.Collection
+}
+
+// creates a new Backend keeping the leases as documents of the collection (leases when empty) |
+// the expiry is compared with the server time ($$NOW) and a ttl index removes the expired documents
+func NewMongoBackend(ctx context.Context, db database.Database, collection string) (Backend, error) {
+
+	// get the mongo database of the client
+	mongoDatabase, err := mongodb.GetMongoDatabase(db)
+	if err != nil {
+		return nil, err
+	}
+	if collection == "" {
+		collection = default_mongo_collection
+	}
+
+	// create the ttl index
+	leases := mongoDatabase.Collection(collection)
+	_, err = leases.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: lease_expires_at_field, Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, datastore.Wrap(datastore.ErrConnection, "failed to create the ttl index of "+collection, err)
+	}
+	return &mongoBackend{
+		collection: leases,
+	}, nil
+}
+
+func (mb *mongoBackend) AcquireOrRenew(ctx context.Context, name string, identity string, ttl time.Duration) (bool, error) {
+
+	// match the lease when the identity holds it or when it expired, a lease held by another identity
+	// makes the upsert fail with a duplicate key error
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{lease_holder_field: identity},
+			bson.M{"$expr": bson.M{"$lte": bson.A{"$" + lease_expires_at_field, "$$NOW"}}},
+		},
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			lease_holder_field:     identity,
+			lease_expires_at_field: bson.M{"$add": bson.A{"$$NOW", ttl.Milliseconds()}},
+		}}},
+	}
+	_, err := mb.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, datastore.Wrap(datastore.ErrConnection, "failed to acquire the lease "+name, err)
+	}
+	return true, nil
+}
+
+func (mb *mongoBackend) Release(ctx context.Context, name string, identity string) error {
+	_, err := mb.collection.DeleteOne(ctx, bson.M{"_id": name, lease_holder_field: identity})
+	if err != nil {
+		return datastore.Wrap(datastore.ErrConnection, "failed to release the lease "+name, err)
+	}
+	return nil
+}
+
+func (mb *mongoBackend) Holder(ctx context.Context, name string) (string, error) {
+	var current lease
+	err := mb.collection.FindOne(ctx, bson.M{
+		"_id":   name,
+		"$expr": bson.M{"$gt": bson.A{"$" + lease_expires_at_field, "$$NOW"}},
+	}).Decode(&current)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+	if err != nil {
+		return "", datastore.Wrap(datastore.ErrConnection, "failed to get the lease "+name, err)
+	}
+	return current.Holder, nil
+}
@@ -0,0 +1,84 @@
+package election
+
+import (
+	"context"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/gnanasuryateja/golib/datastore/cache"
+	redisstore "github.com/gnanasuryateja/golib/datastore/cache/redis"
+)
+
+const default_redis_key_prefix = "election:"
+
+// acquireOrRenew sets the lease when it is free and extends it when the identity holds it
+var acquireOrRenewScript = redis.NewScript(`
+local holder = redis.call('GET', KEYS[1])
+if not holder then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+if holder == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// release deletes the lease only when the identity holds it
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+type redisBackend struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// creates a new Backend keeping the leases as redis keys with a ttl, election: is used when keyPrefix is empty
+func NewRedisBackend(c cache.Cache, keyPrefix string) (Backend, error) {
+
+	// get the go-redis client of the cache
+	client, err := redisstore.GetRedisClient(c)
+	if err != nil {
+		return nil, err
+	}
+	if keyPrefix == "" {
+		keyPrefix = default_redis_key_prefix
+	}
+	return &redisBackend{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}, nil
+}
+
+func (rb *redisBackend) AcquireOrRenew(ctx context.Context, name string, identity string, ttl time.Duration) (bool, error) {
+	held, err := acquireOrRenewScript.Run(ctx, rb.client, []string{rb.keyPrefix + name}, identity, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, redisstore.WrapError(err, "failed to acquire the lease "+name)
+	}
+	return held == 1, nil
+}
+
+func (rb *redisBackend) Release(ctx context.Context, name string, identity string) error {
+	err := releaseScript.Run(ctx, rb.client, []string{rb.keyPrefix + name}, identity).Err()
+	if err != nil {
+		return redisstore.WrapError(err, "failed to release the lease "+name)
+	}
+	return nil
+}
+
+func (rb *redisBackend) Holder(ctx context.Context, name string) (string, error) {
+	holder, err := rb.client.Get(ctx, rb.keyPrefix+name).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", redisstore.WrapError(err, "failed to get the lease "+name)
+	}
+	return holder, nil
+}
@@ -0,0 +1,231 @@
+package errors
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+
+	"github.com/gnanasuryateja/golib/constants"
+)
+
+// Error is the structured error used across golib |
+// it works with errors.Is (identity of the error it was derived from) and errors.As
+type Error struct {
+	Code     string         // Code classifies the error, one of constants.ERR_CODE_* |
+	Type     string         // Type is one of constants.ERR_TYPE_STD or constants.ERR_TYPE_GRPC |
+	Message  string         // Message is the human readable description of the error |
+	Metadata map[string]any // Metadata carries additional details about the error |
+	Cause    error          // Cause is the underlying error, if any
+	parent   *Error
+}
+
+// returns the error string in the format [CODE] message: cause
+func (e *Error) Error() string {
+	msg := "[" + e.Code + "] " + e.Message
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+// returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// reports whether target is e or any error e was derived from
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	for current := e; current != nil; current = current.parent {
+		if current == t {
+			return true
+		}
+	}
+	return false
+}
+
+// returns a copy of e which still matches e with errors.Is
+func (e *Error) derive() *Error {
+	derived := *e
+	derived.Metadata = make(map[string]any, len(e.Metadata))
+	for key, value := range e.Metadata {
+		derived.Metadata[key] = value
+	}
+	derived.parent = e
+	return &derived
+}
+
+// returns a copy of e with the cause set
+func (e *Error) Wrap(cause error) *Error {
+	derived := e.derive()
+	derived.Cause = cause
+	return derived
+}
+
+// returns a copy of e with the message replaced
+func (e *Error) WithMessage(message string) *Error {
+	derived := e.derive()
+	derived.Message = message
+	return derived
+}
+
+// returns a copy of e with the formatted message
+func (e *Error) WithMessagef(format string, args ...any) *Error {
+	return e.WithMessage(fmt.Sprintf(format, args...))
+}
+
+// returns a copy of e with the key value added to the metadata
+func (e *Error) WithMetadata(key string, value any) *Error {
+	derived := e.derive()
+	derived.Metadata[key] = value
+	return derived
+}
+
+// returns a copy of e with the error type set
+func (e *Error) WithType(errType string) *Error {
+	derived := e.derive()
+	derived.Type = errType
+	return derived
+}
+
+// creates a new Error with the given code and message
+func New(code string, message string) *Error {
+	return &Error{
+		Code:     code,
+		Type:     constants.ERR_TYPE_STD,
+		Message:  message,
+		Metadata: map[string]any{},
+	}
+}
+
+// creates a new Error with the given code and formatted message
+func Newf(code string, format string, args ...any) *Error {
+	return New(code, fmt.Sprintf(format, args...))
+}
+
+// creates a new Error with the given code and message wrapping the cause
+func Wrap(cause error, code string, message string) *Error {
+	err := New(code, message)
+	err.Cause = cause
+	return err
+}
+
+// creates a new Error with the given code and formatted message wrapping the cause
+func Wrapf(cause error, code string, format string, args ...any) *Error {
+	return Wrap(cause, code, fmt.Sprintf(format, args...))
+}
+
+func NotFound(message string) *Error {
+	return New(constants.ERR_CODE_NOT_FOUND, message)
+}
+
+func InvalidArgument(message string) *Error {
+	return New(constants.ERR_CODE_INVALID_ARGUMENT, message)
+}
+
+func Unavailable(message string) *Error {
+	return New(constants.ERR_CODE_UNAVAILABLE, message)
+}
+
+func Internal(message string) *Error {
+	return New(constants.ERR_CODE_INTERNAL, message)
+}
+
+func AlreadyExists(message string) *Error {
+	return New(constants.ERR_CODE_ALREADY_EXISTS, message)
+}
+
+func Conflict(message string) *Error {
+	return New(constants.ERR_CODE_CONFLICT, message)
+}
+
+func PermissionDenied(message string) *Error {
+	return New(constants.ERR_CODE_PERMISSION_DENIED, message)
+}
+
+func Unauthenticated(message string) *Error {
+	return New(constants.ERR_CODE_UNAUTHENTICATED, message)
+}
+
+func ResourceExhausted(message string) *Error {
+	return New(constants.ERR_CODE_RESOURCE_EXHAUSTED, message)
+}
+
+func FailedPrecondition(message string) *Error {
+	return New(constants.ERR_CODE_FAILED_PRECONDITION, message)
+}
+
+func DeadlineExceeded(message string) *Error {
+	return New(constants.ERR_CODE_DEADLINE_EXCEEDED, message)
+}
+
+func Canceled(message string) *Error {
+	return New(constants.ERR_CODE_CANCELED, message)
+}
+
+func Unimplemented(message string) *Error {
+	return New(constants.ERR_CODE_UNIMPLEMENTED, message)
+}
+
+// returns the code of the first Error in the chain of err |
+// context errors are classified as CANCELED and DEADLINE_EXCEEDED, everything else as UNKNOWN
+func Code(err error) string {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if goerrors.As(err, &e) {
+		return e.Code
+	}
+	if goerrors.Is(err, context.Canceled) {
+		return constants.ERR_CODE_CANCELED
+	}
+	if goerrors.Is(err, context.DeadlineExceeded) {
+		return constants.ERR_CODE_DEADLINE_EXCEEDED
+	}
+	return constants.ERR_CODE_UNKNOWN
+}
+
+// reports whether the code of err is one of the passed codes
+func IsCode(err error, codes ...string) bool {
+	errCode := Code(err)
+	for _, code := range codes {
+		if errCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// converts any error into an Error, wrapping it as UNKNOWN (or the context code) when needed
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if goerrors.As(err, &e) {
+		return e
+	}
+	return Wrap(err, Code(err), err.Error())
+}
+
+// the standard library helpers are re-exported so that callers need a single import
+
+func Is(err, target error) bool {
+	return goerrors.Is(err, target)
+}
+
+func As(err error, target any) bool {
+	return goerrors.As(err, target)
+}
+
+func Unwrap(err error) error {
+	return goerrors.Unwrap(err)
+}
+
+func Join(errs ...error) error {
+	return goerrors.Join(errs...)
+}
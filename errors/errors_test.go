@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/gnanasuryateja/golib/constants"
+)
+
+func TestIs(t *testing.T) {
+	sentinel := NotFound("not found")
+	other := NotFound("not found")
+	derived := sentinel.WithMessage("user not found")
+	twiceDerived := derived.WithMetadata("id", 1).Wrap(goerrors.New("boom"))
+	cause := goerrors.New("cause")
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{"same error", sentinel, sentinel, true},
+		{"derived matches its parent", derived, sentinel, true},
+		{"derived twice matches the root", twiceDerived, sentinel, true},
+		{"derived twice matches the intermediate", twiceDerived, derived, true},
+		{"parent does not match the derived", sentinel, derived, false},
+		{"same code and message is not the same error", other, sentinel, false},
+		{"derived does not match an unrelated error", derived, other, false},
+		{"wrapped with fmt", fmt.Errorf("ctx: %w", derived), sentinel, true},
+		{"cause is reachable", sentinel.Wrap(cause), cause, true},
+		{"non Error target", sentinel, cause, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeriveDoesNotMutateParent(t *testing.T) {
+	sentinel := Conflict("conflict")
+	_ = sentinel.WithMetadata("key", "value").WithMessage("changed")
+	if sentinel.Message != "conflict" {
+		t.Errorf("parent message changed to %q", sentinel.Message)
+	}
+	if len(sentinel.Metadata) != 0 {
+		t.Errorf("parent metadata changed to %v", sentinel.Metadata)
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"error", Unavailable("down"), constants.ERR_CODE_UNAVAILABLE},
+		{"wrapped error", fmt.Errorf("ctx: %w", InvalidArgument("bad")), constants.ERR_CODE_INVALID_ARGUMENT},
+		{"canceled", context.Canceled, constants.ERR_CODE_CANCELED},
+		{"deadline exceeded", fmt.Errorf("ctx: %w", context.DeadlineExceeded), constants.ERR_CODE_DEADLINE_EXCEEDED},
+		{"plain error", goerrors.New("plain"), constants.ERR_CODE_UNKNOWN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Code(tt.err); got != tt.want {
+				t.Errorf("Code(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	err := Internal("failed").Wrap(goerrors.New("boom"))
+	if got, want := err.Error(), "["+constants.ERR_CODE_INTERNAL+"] failed: boom"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gnanasuryateja/golib/constants"
+)
+
+// converts the error into a grpc status carrying an ErrorInfo detail with the error code as reason
+func (m *Mapper) ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	// errors which already are grpc statuses are passed through
+	if st, ok := status.FromError(err); ok && !isError(err) {
+		return st
+	}
+
+	e := From(err)
+	message, metadata := m.exposed(e)
+	st := status.New(m.GRPCCode(e.Code), message)
+
+	// build the error info detail
+	errorInfo := &errdetails.ErrorInfo{
+		Reason:   e.Code,
+		Domain:   m.domain,
+		Metadata: make(map[string]string, len(metadata)),
+	}
+	for key, value := range metadata {
+		errorInfo.Metadata[key] = fmt.Sprint(value)
+	}
+	stWithDetails, detailsErr := st.WithDetails(errorInfo)
+	if detailsErr != nil {
+		return st
+	}
+	return stWithDetails
+}
+
+// converts the error into a grpc error which can be returned from a grpc handler
+func (m *Mapper) ToGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return m.ToGRPCStatus(err).Err()
+}
+
+// converts the grpc status into an Error, the code is read from the ErrorInfo detail when present
+func (m *Mapper) FromGRPCStatus(st *status.Status) *Error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+	e := New(m.CodeFromGRPC(st.Code()), st.Message())
+	e.Type = constants.ERR_TYPE_GRPC
+	for _, detail := range st.Details() {
+		errorInfo, ok := detail.(*errdetails.ErrorInfo)
+		if !ok {
+			continue
+		}
+		if errorInfo.Reason != "" {
+			e.Code = errorInfo.Reason
+		}
+		for key, value := range errorInfo.Metadata {
+			e.Metadata[key] = value
+		}
+	}
+	return e
+}
+
+// converts an error returned by a grpc client into an Error
+func (m *Mapper) FromGRPCError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return From(err)
+	}
+	e := m.FromGRPCStatus(st)
+
+	// an error reporting an OK status has no grpc code to map
+	if e == nil {
+		return From(err)
+	}
+	e.Cause = err
+	return e
+}
+
+// reports whether err has an Error in its chain
+func isError(err error) bool {
+	var e *Error
+	return As(err, &e)
+}
@@ -0,0 +1,44 @@
+package errors
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gnanasuryateja/golib/constants"
+)
+
+// okStatusError is an error whose grpc status is OK
+type okStatusError struct{}
+
+func (okStatusError) Error() string              { return "ok status" }
+func (okStatusError) GRPCStatus() *status.Status { return status.New(codes.OK, "") }
+
+func TestFromGRPCError(t *testing.T) {
+	mapper := NewMapper(MapperConfig{})
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"not found status", status.Error(codes.NotFound, "missing"), constants.ERR_CODE_NOT_FOUND},
+		{"ok status", okStatusError{}, constants.ERR_CODE_UNKNOWN},
+		{"round trip", mapper.ToGRPCError(Conflict("conflict")), constants.ERR_CODE_CONFLICT},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := mapper.FromGRPCError(tt.err)
+			if e == nil {
+				t.Fatalf("FromGRPCError(%v) = nil", tt.err)
+			}
+			if e.Code != tt.want {
+				t.Errorf("FromGRPCError(%v).Code = %q, want %q", tt.err, e.Code, tt.want)
+			}
+			if !Is(e, tt.err) && e.Cause != tt.err {
+				t.Errorf("FromGRPCError(%v) lost the cause", tt.err)
+			}
+		})
+	}
+}
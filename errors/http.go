@@ -0,0 +1,101 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gnanasuryateja/golib/constants"
+)
+
+const problem_content_type = "application/problem+json"
+
+// Problem is the RFC 7807 problem details object |
+// Code and Metadata are sent as extension members
+type Problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Code     string         `json:"code,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// converts the error into a problem
+func (m *Mapper) ToProblem(err error) Problem {
+	e := From(err)
+	if e == nil {
+		e = Internal("")
+	}
+	message, metadata := m.exposed(e)
+	httpStatus := m.HTTPStatus(e.Code)
+	problem := Problem{
+		Type:     problem_type_default,
+		Title:    http.StatusText(httpStatus),
+		Status:   httpStatus,
+		Detail:   message,
+		Code:     e.Code,
+		Metadata: metadata,
+	}
+	if m.problemTypeBase != "" {
+		problem.Type = strings.TrimSuffix(m.problemTypeBase, "/") + "/" + strings.ToLower(strings.ReplaceAll(e.Code, "_", "-"))
+	}
+	if problem.Title == "" {
+		problem.Title = e.Code
+	}
+	return problem
+}
+
+// writes the error as an application/problem+json response |
+// the request path is set as the problem instance when the request is passed
+func (m *Mapper) WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	problem := m.ToProblem(err)
+	if r != nil && r.URL != nil {
+		problem.Instance = r.URL.Path
+	}
+	w.Header().Set("Content-Type", problem_content_type)
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+// converts the problem into an Error
+func (m *Mapper) FromProblem(problem Problem) *Error {
+	code := problem.Code
+	if code == "" {
+		code = m.CodeFromHTTP(problem.Status)
+	}
+	message := problem.Detail
+	if message == "" {
+		message = problem.Title
+	}
+	e := New(code, message)
+	e.Type = constants.ERR_TYPE_STD
+	for key, value := range problem.Metadata {
+		e.Metadata[key] = value
+	}
+	return e
+}
+
+// converts an unsuccessful http response into an Error, returns nil for successful responses |
+// the body is decoded as a problem when the response has the problem+json content type
+func (m *Mapper) FromHTTPResponse(resp *http.Response) *Error {
+	if resp == nil || resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	problem := Problem{
+		Status: resp.StatusCode,
+		Title:  http.StatusText(resp.StatusCode),
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), problem_content_type) && resp.Body != nil {
+		body, err := io.ReadAll(resp.Body)
+		if err == nil {
+			_ = json.Unmarshal(body, &problem)
+		}
+	}
+	if problem.Status == 0 {
+		problem.Status = resp.StatusCode
+	}
+	return m.FromProblem(problem)
+}
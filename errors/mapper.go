@@ -0,0 +1,177 @@
+package errors
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/gnanasuryateja/golib/constants"
+)
+
+const (
+	problem_type_default     = "about:blank"
+	internal_message_default = "an internal error occurred"
+	metadata_cause_key       = "cause"
+)
+
+// default mapping from error codes to grpc codes
+var defaultGRPCCodes = map[string]codes.Code{
+	constants.ERR_CODE_UNKNOWN:             codes.Unknown,
+	constants.ERR_CODE_INTERNAL:            codes.Internal,
+	constants.ERR_CODE_INVALID_ARGUMENT:    codes.InvalidArgument,
+	constants.ERR_CODE_NOT_FOUND:           codes.NotFound,
+	constants.ERR_CODE_ALREADY_EXISTS:      codes.AlreadyExists,
+	constants.ERR_CODE_CONFLICT:            codes.Aborted,
+	constants.ERR_CODE_PERMISSION_DENIED:   codes.PermissionDenied,
+	constants.ERR_CODE_UNAUTHENTICATED:     codes.Unauthenticated,
+	constants.ERR_CODE_RESOURCE_EXHAUSTED:  codes.ResourceExhausted,
+	constants.ERR_CODE_FAILED_PRECONDITION: codes.FailedPrecondition,
+	constants.ERR_CODE_UNAVAILABLE:         codes.Unavailable,
+	constants.ERR_CODE_DEADLINE_EXCEEDED:   codes.DeadlineExceeded,
+	constants.ERR_CODE_CANCELED:            codes.Canceled,
+	constants.ERR_CODE_UNIMPLEMENTED:       codes.Unimplemented,
+}
+
+// default mapping from error codes to http status codes
+var defaultHTTPStatuses = map[string]int{
+	constants.ERR_CODE_UNKNOWN:             http.StatusInternalServerError,
+	constants.ERR_CODE_INTERNAL:            http.StatusInternalServerError,
+	constants.ERR_CODE_INVALID_ARGUMENT:    http.StatusBadRequest,
+	constants.ERR_CODE_NOT_FOUND:           http.StatusNotFound,
+	constants.ERR_CODE_ALREADY_EXISTS:      http.StatusConflict,
+	constants.ERR_CODE_CONFLICT:            http.StatusConflict,
+	constants.ERR_CODE_PERMISSION_DENIED:   http.StatusForbidden,
+	constants.ERR_CODE_UNAUTHENTICATED:     http.StatusUnauthorized,
+	constants.ERR_CODE_RESOURCE_EXHAUSTED:  http.StatusTooManyRequests,
+	constants.ERR_CODE_FAILED_PRECONDITION: http.StatusPreconditionFailed,
+	constants.ERR_CODE_UNAVAILABLE:         http.StatusServiceUnavailable,
+	constants.ERR_CODE_DEADLINE_EXCEEDED:   http.StatusGatewayTimeout,
+	constants.ERR_CODE_CANCELED:            499,
+	constants.ERR_CODE_UNIMPLEMENTED:       http.StatusNotImplemented,
+}
+
+type MapperConfig struct {
+	Env             string                // Env is the environment in which the application is running, internal messages are hidden in constants.ENV_PROD |
+	GRPCCodes       map[string]codes.Code // GRPCCodes overrides the default error code to grpc code mapping |
+	HTTPStatuses    map[string]int        // HTTPStatuses overrides the default error code to http status mapping |
+	Domain          string                // Domain is set on the grpc ErrorInfo detail |
+	ProblemTypeBase string                // ProblemTypeBase is prefixed to the lower cased code to build the problem type, about:blank is used when empty
+}
+
+// Mapper converts errors to grpc statuses and http problems and back
+type Mapper struct {
+	env             string
+	domain          string
+	problemTypeBase string
+	grpcCodes       map[string]codes.Code
+	httpStatuses    map[string]int
+	codesFromGRPC   map[codes.Code]string
+	codesFromHTTP   map[int]string
+}
+
+// creates a new Mapper merging the passed mappings over the defaults
+func NewMapper(mapperConfig MapperConfig) *Mapper {
+	mapper := &Mapper{
+		env:             mapperConfig.Env,
+		domain:          mapperConfig.Domain,
+		problemTypeBase: mapperConfig.ProblemTypeBase,
+		grpcCodes:       map[string]codes.Code{},
+		httpStatuses:    map[string]int{},
+		codesFromGRPC:   map[codes.Code]string{},
+		codesFromHTTP:   map[int]string{},
+	}
+	for code, grpcCode := range defaultGRPCCodes {
+		mapper.grpcCodes[code] = grpcCode
+	}
+	for code, grpcCode := range mapperConfig.GRPCCodes {
+		mapper.grpcCodes[code] = grpcCode
+	}
+	for code, httpStatus := range defaultHTTPStatuses {
+		mapper.httpStatuses[code] = httpStatus
+	}
+	for code, httpStatus := range mapperConfig.HTTPStatuses {
+		mapper.httpStatuses[code] = httpStatus
+	}
+
+	// build the reverse mappings, the first code (in sorted order) wins when several codes share a status
+	for _, code := range sortedKeys(mapper.grpcCodes) {
+		if _, ok := mapper.codesFromGRPC[mapper.grpcCodes[code]]; !ok {
+			mapper.codesFromGRPC[mapper.grpcCodes[code]] = code
+		}
+	}
+	for _, code := range sortedKeys(mapper.httpStatuses) {
+		if _, ok := mapper.codesFromHTTP[mapper.httpStatuses[code]]; !ok {
+			mapper.codesFromHTTP[mapper.httpStatuses[code]] = code
+		}
+	}
+	return mapper
+}
+
+// returns the grpc code for the error code
+func (m *Mapper) GRPCCode(code string) codes.Code {
+	if grpcCode, ok := m.grpcCodes[code]; ok {
+		return grpcCode
+	}
+	return codes.Unknown
+}
+
+// returns the http status for the error code
+func (m *Mapper) HTTPStatus(code string) int {
+	if httpStatus, ok := m.httpStatuses[code]; ok {
+		return httpStatus
+	}
+	return http.StatusInternalServerError
+}
+
+// returns the error code for the grpc code
+func (m *Mapper) CodeFromGRPC(grpcCode codes.Code) string {
+	if code, ok := m.codesFromGRPC[grpcCode]; ok {
+		return code
+	}
+	return constants.ERR_CODE_UNKNOWN
+}
+
+// returns the error code for the http status
+func (m *Mapper) CodeFromHTTP(httpStatus int) string {
+	if code, ok := m.codesFromHTTP[httpStatus]; ok {
+		return code
+	}
+	if httpStatus >= http.StatusInternalServerError {
+		return constants.ERR_CODE_INTERNAL
+	}
+	if httpStatus >= http.StatusBadRequest {
+		return constants.ERR_CODE_INVALID_ARGUMENT
+	}
+	return constants.ERR_CODE_UNKNOWN
+}
+
+// returns the message and metadata which are safe to send to the client |
+// in prod the message and metadata of server side errors are hidden and the cause is dropped
+func (m *Mapper) exposed(e *Error) (string, map[string]any) {
+	if strings.EqualFold(m.env, constants.ENV_PROD) {
+		if m.HTTPStatus(e.Code) >= http.StatusInternalServerError {
+			return internal_message_default, nil
+		}
+		return e.Message, e.Metadata
+	}
+	metadata := make(map[string]any, len(e.Metadata)+1)
+	for key, value := range e.Metadata {
+		metadata[key] = value
+	}
+	if e.Cause != nil {
+		metadata[metadata_cause_key] = e.Cause.Error()
+	}
+	return e.Message, metadata
+}
+
+// returns the keys of the map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -21,12 +21,12 @@ package main
 // 		Username: "",
 // 		Password: "",
 // 	}
-// 	db, cancel, err := mongodb.NewMongoStoreClient(ctx, mongoStoreConfig)
+// 	db, err := mongodb.NewMongoStoreClient(ctx, mongoStoreConfig)
 // 	if err != nil {
 // 		fmt.Println(err)
 // 		return
 // 	}
-// 	defer db.CloseDB(ctx, cancel)
+// 	defer db.Close(ctx)
 // 	// testing db.HealthCheck
 // 	err = db.HealthCheck(ctx)
 // 	if err != nil {
@@ -25,6 +25,7 @@ package main
 // 		fmt.Println(err)
 // 		return
 // 	}
+// 	defer cache.Close(ctx)
 // 	// testing cache.HealthCheck
 // 	err = cache.HealthCheck(ctx)
 // 	if err != nil {
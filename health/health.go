@@ -0,0 +1,217 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/errors"
+	"github.com/gnanasuryateja/golib/validation"
+)
+
+const (
+	default_timeout   = 5 * time.Second
+	default_cache_ttl = 2 * time.Second
+)
+
+// HealthChecker is implemented by cache.Cache, database.Database and messagingqueue.MessageQueue
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+type Check struct {
+	Name     string                          `validate:"required"` // Name identifies the component in the reports |
+	Check    func(ctx context.Context) error `validate:"required"` // Check returns an error when the component is unhealthy, e.g. redisClient.HealthCheck |
+	Timeout  time.Duration                   `validate:"min=0"`    // Timeout bounds a single run of the check, 5s by default |
+	Critical bool                            // Critical checks make the readiness fail, non critical ones only degrade it |
+	Liveness bool                            // Liveness includes the check in /healthz, which has no checks by default
+}
+
+// validates the input params
+func (c Check) validate() error {
+	return validation.Validate(c)
+}
+
+type CheckerConfig struct {
+	CacheTTL time.Duration // CacheTTL is how long the result of a check is reused, 2s by default |
+	Clock    clock.Clock   // Clock stamps the results and measures the latency, the real clock by default
+}
+
+// ComponentStatus is the result of a single check
+type ComponentStatus struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	LatencyMs float64   `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the aggregated result of the checks
+type Report struct {
+	Status     string            `json:"status"`
+	Components []ComponentStatus `json:"components"`
+}
+
+// Checker runs the registered checks concurrently and caches their results
+type Checker struct {
+	cacheTTL time.Duration
+	clock    clock.Clock
+	lock     sync.RWMutex
+	checks   map[string]Check
+	results  map[string]ComponentStatus
+	group    singleflight.Group
+}
+
+// creates a new Checker
+func NewChecker(checkerConfig CheckerConfig) *Checker {
+	if checkerConfig.CacheTTL <= 0 {
+		checkerConfig.CacheTTL = default_cache_ttl
+	}
+	return &Checker{
+		cacheTTL: checkerConfig.CacheTTL,
+		clock:    clock.OrReal(checkerConfig.Clock),
+		checks:   map[string]Check{},
+		results:  map[string]ComponentStatus{},
+	}
+}
+
+// registers the check, the names must be unique
+func (c *Checker) Register(check Check) error {
+
+	// validate the check
+	err := check.validate()
+	if err != nil {
+		return err
+	}
+	if check.Timeout == 0 {
+		check.Timeout = default_timeout
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.checks[check.Name]; ok {
+		return errors.AlreadyExists(fmt.Sprintf("check %s is already registered", check.Name))
+	}
+	c.checks[check.Name] = check
+	return nil
+}
+
+// registers a datastore client (or anything with a HealthCheck method) as a check
+func (c *Checker) RegisterHealthChecker(name string, healthChecker HealthChecker, critical bool) error {
+	return c.Register(Check{
+		Name:     name,
+		Check:    healthChecker.HealthCheck,
+		Critical: critical,
+	})
+}
+
+// runs the liveness checks
+func (c *Checker) Liveness(ctx context.Context) Report {
+	return c.run(ctx, true)
+}
+
+// runs all the checks
+func (c *Checker) Readiness(ctx context.Context) Report {
+	return c.run(ctx, false)
+}
+
+func (c *Checker) run(ctx context.Context, liveness bool) Report {
+	c.lock.RLock()
+	var checks []Check
+	for _, check := range c.checks {
+		if !liveness || check.Liveness {
+			checks = append(checks, check)
+		}
+	}
+	c.lock.RUnlock()
+
+	// run the checks concurrently
+	components := make([]ComponentStatus, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			components[i] = c.runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].Name < components[j].Name
+	})
+
+	// aggregate the statuses
+	report := Report{
+		Status:     constants.HEALTH_STATUS_UP,
+		Components: components,
+	}
+	for _, component := range components {
+		if component.Status == constants.HEALTH_STATUS_UP {
+			continue
+		}
+		if component.Critical {
+			report.Status = constants.HEALTH_STATUS_DOWN
+			break
+		}
+		report.Status = constants.HEALTH_STATUS_DEGRADED
+	}
+	return report
+}
+
+// runs the check, the cached result is returned when it is fresh and concurrent runs are collapsed
+func (c *Checker) runCheck(ctx context.Context, check Check) ComponentStatus {
+	c.lock.RLock()
+	cached, ok := c.results[check.Name]
+	c.lock.RUnlock()
+	if ok && c.clock.Since(cached.CheckedAt) < c.cacheTTL {
+		return cached
+	}
+
+	result, _, _ := c.group.Do(check.Name, func() (any, error) {
+		checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), check.Timeout)
+		defer cancel()
+
+		start := c.clock.Now()
+		err := runWithTimeout(checkCtx, check)
+		component := ComponentStatus{
+			Name:      check.Name,
+			Status:    constants.HEALTH_STATUS_UP,
+			Critical:  check.Critical,
+			LatencyMs: float64(c.clock.Since(start).Microseconds()) / 1000,
+			CheckedAt: start,
+		}
+		if err != nil {
+			component.Status = constants.HEALTH_STATUS_DOWN
+			component.Error = err.Error()
+		}
+
+		c.lock.Lock()
+		c.results[check.Name] = component
+		c.lock.Unlock()
+		return component, nil
+	})
+	return result.(ComponentStatus)
+}
+
+// runs the check in a goroutine so that a check ignoring its ctx (e.g. the kafka metadata refresh) cannot block past the Timeout |
+// the abandoned check keeps running until it returns, its result is dropped
+func runWithTimeout(ctx context.Context, check Check) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- check.Check(ctx)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return errors.DeadlineExceeded(fmt.Sprintf("check %s timed out after %s", check.Name, check.Timeout))
+	}
+}
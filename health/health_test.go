@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gnanasuryateja/golib/constants"
+)
+
+func TestCheckIgnoringCtxTimesOut(t *testing.T) {
+	checker := NewChecker(CheckerConfig{})
+	release := make(chan struct{})
+	defer close(release)
+	err := checker.Register(Check{
+		Name: "stuck",
+		Check: func(ctx context.Context) error {
+			// ignores ctx like a call without one would
+			<-release
+			return nil
+		},
+		Timeout:  20 * time.Millisecond,
+		Critical: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan Report, 1)
+	go func() {
+		done <- checker.Readiness(context.Background())
+	}()
+	select {
+	case report := <-done:
+		if report.Status != constants.HEALTH_STATUS_DOWN {
+			t.Errorf("Status = %s, want %s", report.Status, constants.HEALTH_STATUS_DOWN)
+		}
+		if len(report.Components) != 1 || report.Components[0].Error == "" {
+			t.Errorf("Components = %+v, want the timeout error", report.Components)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Readiness blocked past the check Timeout")
+	}
+}
+
+func TestReadinessAggregation(t *testing.T) {
+	checker := NewChecker(CheckerConfig{})
+	failing := func(ctx context.Context) error { return context.Canceled }
+	healthy := func(ctx context.Context) error { return nil }
+	for _, check := range []Check{
+		{Name: "healthy", Check: healthy, Critical: true, Liveness: true},
+		{Name: "optional", Check: failing},
+	} {
+		if err := checker.Register(check); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if report := checker.Readiness(context.Background()); report.Status != constants.HEALTH_STATUS_DEGRADED {
+		t.Errorf("Readiness().Status = %s, want %s", report.Status, constants.HEALTH_STATUS_DEGRADED)
+	}
+	if report := checker.Liveness(context.Background()); report.Status != constants.HEALTH_STATUS_UP || len(report.Components) != 1 {
+		t.Errorf("Liveness() = %+v, want only the healthy check UP", report)
+	}
+	if err := checker.Register(Check{Name: "healthy", Check: healthy}); err == nil {
+		t.Error("Register() of a duplicate name succeeded")
+	}
+}
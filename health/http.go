@@ -0,0 +1,47 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gnanasuryateja/golib/constants"
+)
+
+const (
+	LIVENESS_PATH  = "/healthz"
+	READINESS_PATH = "/readyz"
+)
+
+// serves the liveness report
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, c.Liveness(r.Context()))
+	})
+}
+
+// serves the readiness report
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, c.Readiness(r.Context()))
+	})
+}
+
+// returns a mux serving /healthz and /readyz
+func (c *Checker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(LIVENESS_PATH, c.LivenessHandler())
+	mux.Handle(READINESS_PATH, c.ReadinessHandler())
+	return mux
+}
+
+// writes the report as json, 503 is returned when the report is down
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == constants.HEALTH_STATUS_DOWN {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,31 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+// ErrInvalidID is returned when an id cannot be parsed or decoded
+var ErrInvalidID = errors.InvalidArgument("invalid id")
+
+// entropy used by the generators
+var entropy io.Reader = rand.Reader
+
+// fills b with random bytes
+func randomBytes(b []byte) {
+	_, err := io.ReadFull(entropy, b)
+	if err != nil {
+		// crypto/rand does not fail on the supported platforms
+		panic("idgen: failed to read random bytes: " + err.Error())
+	}
+}
+
+// returns a random uint16
+func randomUint16() uint16 {
+	var b [2]byte
+	randomBytes(b[:])
+	return binary.BigEndian.Uint16(b[:])
+}
@@ -0,0 +1,200 @@
+package idgen
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+const (
+	snowflake_node_bits_default     = 10
+	snowflake_sequence_bits_default = 12
+	snowflake_max_node_and_seq_bits = 22
+)
+
+// default epoch of the snowflake ids (2024-01-01T00:00:00Z)
+var SnowflakeEpochDefault = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+type SnowflakeConfig struct {
+	Node         int64       // Node identifies the generator, it must fit in NodeBits |
+	NodeBits     uint8       // NodeBits is the number of bits of the node, 10 when zero |
+	SequenceBits uint8       // SequenceBits is the number of bits of the per millisecond sequence, 12 when zero |
+	Epoch        time.Time   // Epoch is the start of the timestamps, SnowflakeEpochDefault when zero |
+	Clock        clock.Clock // Clock is the source of time, the real clock when nil
+}
+
+// validates the input params
+func (sc SnowflakeConfig) validate() error {
+	if int(sc.NodeBits)+int(sc.SequenceBits) > snowflake_max_node_and_seq_bits {
+		return errors.InvalidArgument("invalid SnowflakeConfig... NodeBits + SequenceBits must be at most " + strconv.Itoa(snowflake_max_node_and_seq_bits))
+	}
+	if sc.Node < 0 || sc.Node >= 1<<sc.NodeBits {
+		return errors.InvalidArgument("invalid SnowflakeConfig... Node must be between 0 and " + strconv.FormatInt(1<<sc.NodeBits-1, 10))
+	}
+	if sc.Epoch.After(clock.OrReal(sc.Clock).Now()) {
+		return errors.InvalidArgument("invalid SnowflakeConfig... Epoch is in the future")
+	}
+	return nil
+}
+
+// SnowflakeID is a 63 bit id made of the milliseconds since the epoch, the node and the sequence
+type SnowflakeID int64
+
+// SnowflakeParts are the decoded parts of a SnowflakeID
+type SnowflakeParts struct {
+	Time     time.Time // Time is the timestamp of the id |
+	Node     int64     // Node is the generator which created the id |
+	Sequence int64     // Sequence is the position of the id within the millisecond
+}
+
+// Snowflake generates SnowflakeIDs which are monotonic within the generator |
+// when the sequence of a millisecond is exhausted (or the clock goes back) the next millisecond is borrowed
+type Snowflake struct {
+	clock        clock.Clock
+	epochMs      int64
+	node         int64
+	nodeBits     uint8
+	sequenceBits uint8
+	lock         sync.Mutex
+	lastMs       int64
+	sequence     int64
+}
+
+// creates a new Snowflake generator
+func NewSnowflake(snowflakeConfig SnowflakeConfig) (*Snowflake, error) {
+
+	// set the defaults
+	if snowflakeConfig.NodeBits == 0 {
+		snowflakeConfig.NodeBits = snowflake_node_bits_default
+	}
+	if snowflakeConfig.SequenceBits == 0 {
+		snowflakeConfig.SequenceBits = snowflake_sequence_bits_default
+	}
+	if snowflakeConfig.Epoch.IsZero() {
+		snowflakeConfig.Epoch = SnowflakeEpochDefault
+	}
+
+	// validate the snowflakeConfig
+	err := snowflakeConfig.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snowflake{
+		clock:        clock.OrReal(snowflakeConfig.Clock),
+		epochMs:      snowflakeConfig.Epoch.UnixMilli(),
+		node:         snowflakeConfig.Node,
+		nodeBits:     snowflakeConfig.NodeBits,
+		sequenceBits: snowflakeConfig.SequenceBits,
+		lastMs:       -1,
+	}, nil
+}
+
+// returns a new SnowflakeID
+func (s *Snowflake) New() SnowflakeID {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ms := s.clock.Now().UnixMilli() - s.epochMs
+	if ms > s.lastMs {
+		s.lastMs = ms
+		s.sequence = 0
+	} else {
+		s.sequence++
+		if s.sequence >= 1<<s.sequenceBits {
+			s.lastMs++
+			s.sequence = 0
+		}
+	}
+	return SnowflakeID(s.lastMs<<(s.nodeBits+s.sequenceBits) | s.node<<s.sequenceBits | s.sequence)
+}
+
+// decodes the id with the layout of the generator
+func (s *Snowflake) Decode(id SnowflakeID) SnowflakeParts {
+	shift := s.nodeBits + s.sequenceBits
+	return SnowflakeParts{
+		Time:     time.UnixMilli(int64(id)>>shift + s.epochMs).UTC(),
+		Node:     int64(id) >> s.sequenceBits & (1<<s.nodeBits - 1),
+		Sequence: int64(id) & (1<<s.sequenceBits - 1),
+	}
+}
+
+// returns the timestamp of the id with the layout of the generator
+func (s *Snowflake) Time(id SnowflakeID) time.Time {
+	return s.Decode(id).Time
+}
+
+// parses the decimal form
+func ParseSnowflakeID(value string) (SnowflakeID, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, ErrInvalidID.WithMessage("invalid snowflake id " + value).Wrap(err)
+	}
+	return SnowflakeID(id), nil
+}
+
+// returns the decimal form
+func (id SnowflakeID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+func (id SnowflakeID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+func (id *SnowflakeID) UnmarshalText(text []byte) error {
+	parsed, err := ParseSnowflakeID(string(text))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// the id is a json string since javascript numbers lose precision above 2^53
+func (id SnowflakeID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.String())
+}
+
+// both json strings and numbers are accepted
+func (id *SnowflakeID) UnmarshalJSON(data []byte) error {
+	var value string
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return id.UnmarshalText(data)
+	}
+	return id.UnmarshalText([]byte(value))
+}
+
+// the id is stored as a bson int64
+func (id SnowflakeID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.TypeInt64, bsoncore.AppendInt64(nil, int64(id)), nil
+}
+
+// int64 and string values are accepted
+func (id *SnowflakeID) UnmarshalBSONValue(bsonType bsontype.Type, data []byte) error {
+	switch bsonType {
+	case bson.TypeInt64:
+		value, _, ok := bsoncore.ReadInt64(data)
+		if !ok {
+			return ErrInvalidID.WithMessage("invalid bson int64")
+		}
+		*id = SnowflakeID(value)
+		return nil
+	case bson.TypeString:
+		str, _, ok := bsoncore.ReadString(data)
+		if !ok {
+			return ErrInvalidID.WithMessage("invalid bson string")
+		}
+		return id.UnmarshalText([]byte(str))
+	}
+	return ErrInvalidID.WithMessage("cannot unmarshal bson " + bsonType.String() + " into snowflake id")
+}
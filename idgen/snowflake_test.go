@@ -0,0 +1,91 @@
+package idgen
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+func TestSnowflakeConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  SnowflakeConfig
+		wantErr bool
+	}{
+		{"defaults", SnowflakeConfig{Node: 1023}, false},
+		{"node too large", SnowflakeConfig{Node: 1024}, true},
+		{"negative node", SnowflakeConfig{Node: -1}, true},
+		{"too many bits", SnowflakeConfig{NodeBits: 12, SequenceBits: 12}, true},
+		{"epoch in the future", SnowflakeConfig{Epoch: time.Now().Add(time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSnowflake(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSnowflake() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSnowflake(t *testing.T) {
+	now := SnowflakeEpochDefault.Add(time.Hour)
+	fake := clock.NewFake(now)
+	snowflake, err := NewSnowflake(SnowflakeConfig{Node: 5, SequenceBits: 2, Clock: fake})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 4 ids fit in the millisecond, the fifth one borrows the next millisecond
+	var ids []SnowflakeID
+	for i := 0; i < 5; i++ {
+		ids = append(ids, snowflake.New())
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("id %d %d is not after %d", i, ids[i], ids[i-1])
+		}
+	}
+	parts := snowflake.Decode(ids[3])
+	if !parts.Time.Equal(now) || parts.Node != 5 || parts.Sequence != 3 {
+		t.Errorf("Decode() = %+v, want time %v node 5 sequence 3", parts, now)
+	}
+	if got := snowflake.Time(ids[4]); !got.Equal(now.Add(time.Millisecond)) {
+		t.Errorf("Time() = %v, want the borrowed millisecond", got)
+	}
+}
+
+func TestSnowflakeIDEncodings(t *testing.T) {
+	id := SnowflakeID(1<<62 + 7)
+
+	data, err := json.Marshal(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"4611686018427387911"` {
+		t.Errorf("json = %s, want a string", data)
+	}
+	for _, input := range []string{`"4611686018427387911"`, `4611686018427387911`} {
+		var parsed SnowflakeID
+		if err := json.Unmarshal([]byte(input), &parsed); err != nil || parsed != id {
+			t.Errorf("json %s = %d, %v, want %d", input, parsed, err, id)
+		}
+	}
+	var parsed SnowflakeID
+	if err := json.Unmarshal([]byte(`"-1"`), &parsed); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("json negative error = %v, want ErrInvalidID", err)
+	}
+
+	for _, value := range []any{id, id.String()} {
+		doc, _ := bson.Marshal(bson.M{"id": value})
+		var fromBSON struct{ ID SnowflakeID }
+		if err := bson.Unmarshal(doc, &fromBSON); err != nil || fromBSON.ID != id {
+			t.Errorf("bson %T = %d, %v, want %d", value, fromBSON.ID, err, id)
+		}
+	}
+}
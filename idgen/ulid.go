@@ -0,0 +1,208 @@
+package idgen
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+
+	"github.com/gnanasuryateja/golib/clock"
+)
+
+const (
+	ulid_string_length = 26
+	crockford_alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// decoding table of the crockford base32 alphabet, I L and O are decoded as 1 1 and 0
+var crockfordDecoding = func() [256]byte {
+	var table [256]byte
+	for i := range table {
+		table[i] = 0xFF
+	}
+	for i := 0; i < len(crockford_alphabet); i++ {
+		table[crockford_alphabet[i]] = byte(i)
+		table[crockford_alphabet[i]|0x20] = byte(i)
+	}
+	for _, alias := range [][2]byte{{'I', 1}, {'L', 1}, {'O', 0}} {
+		table[alias[0]] = alias[1]
+		table[alias[0]|0x20] = alias[1]
+	}
+	return table
+}()
+
+// ULID is a 48 bit millisecond timestamp followed by 80 random bits, encoded in 26 crockford base32 characters
+type ULID [16]byte
+
+// ULIDGenerator generates ULIDs which are monotonic within the same millisecond |
+// the random part of the previous ULID is incremented when the millisecond did not change
+type ULIDGenerator struct {
+	clock  clock.Clock
+	lock   sync.Mutex
+	lastMs int64
+	last   ULID
+}
+
+// creates a new ULIDGenerator, the real clock is used when c is nil
+func NewULIDGenerator(c clock.Clock) *ULIDGenerator {
+	return &ULIDGenerator{
+		clock: clock.OrReal(c),
+	}
+}
+
+var defaultULIDGenerator = NewULIDGenerator(nil)
+
+// returns a new ULID from the default generator
+func NewULID() ULID {
+	return defaultULIDGenerator.New()
+}
+
+// returns a new ULID
+func (g *ULIDGenerator) New() ULID {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	ms := g.clock.Now().UnixMilli()
+	if ms > g.lastMs || !incrementEntropy(&g.last) {
+		// new millisecond (or the random part overflowed), the next millisecond is borrowed when the clock did not move
+		if ms <= g.lastMs {
+			ms = g.lastMs + 1
+		}
+		g.lastMs = ms
+		randomBytes(g.last[6:])
+	}
+	g.last[0] = byte(g.lastMs >> 40)
+	g.last[1] = byte(g.lastMs >> 32)
+	g.last[2] = byte(g.lastMs >> 24)
+	g.last[3] = byte(g.lastMs >> 16)
+	g.last[4] = byte(g.lastMs >> 8)
+	g.last[5] = byte(g.lastMs)
+	return g.last
+}
+
+// increments the 80 bit random part, false is returned on overflow
+func incrementEntropy(id *ULID) bool {
+	for i := len(id) - 1; i >= 6; i-- {
+		id[i]++
+		if id[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// parses the 26 character crockford base32 form, the parsing is case insensitive
+func ParseULID(value string) (ULID, error) {
+	var id ULID
+	if len(value) != ulid_string_length {
+		return id, ErrInvalidID.WithMessage("invalid ulid length " + value)
+	}
+
+	// the first character holds only 3 bits, anything above 7 overflows the 128 bits
+	var digits [ulid_string_length]byte
+	for i := 0; i < ulid_string_length; i++ {
+		digits[i] = crockfordDecoding[value[i]]
+		if digits[i] == 0xFF {
+			return id, ErrInvalidID.WithMessage("invalid ulid character in " + value)
+		}
+	}
+	if digits[0] > 7 {
+		return id, ErrInvalidID.WithMessage("ulid overflows 128 bits " + value)
+	}
+
+	// 26 characters of 5 bits are read as 130 bits, the top 2 bits are zero
+	var acc uint64
+	var bits uint
+	pos := 0
+	for i, digit := range digits {
+		acc = acc<<5 | uint64(digit)
+		bits += 5
+		if i == 0 {
+			bits -= 2
+		}
+		for bits >= 8 {
+			bits -= 8
+			id[pos] = byte(acc >> bits)
+			pos++
+		}
+	}
+	return id, nil
+}
+
+// returns the timestamp of the ULID
+func (u ULID) Time() time.Time {
+	ms := int64(u[0])<<40 | int64(u[1])<<32 | int64(u[2])<<24 | int64(u[3])<<16 | int64(u[4])<<8 | int64(u[5])
+	return time.UnixMilli(ms).UTC()
+}
+
+// reports whether the ULID is all zeros
+func (u ULID) IsZero() bool {
+	return u == ULID{}
+}
+
+// returns the 26 character crockford base32 form
+func (u ULID) String() string {
+	var buf [ulid_string_length]byte
+
+	// 128 bits are written as 130 bits, from the last character to the first
+	var acc uint64
+	var bits uint
+	pos := ulid_string_length - 1
+	for i := len(u) - 1; i >= 0; i-- {
+		acc |= uint64(u[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			buf[pos] = crockford_alphabet[acc&0x1F]
+			pos--
+			acc >>= 5
+			bits -= 5
+		}
+	}
+	buf[0] = crockford_alphabet[acc&0x1F]
+	return string(buf[:])
+}
+
+func (u ULID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *ULID) UnmarshalText(text []byte) error {
+	parsed, err := ParseULID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+func (u ULID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.String())
+}
+
+func (u *ULID) UnmarshalJSON(data []byte) error {
+	var value string
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return ErrInvalidID.WithMessage("ulid must be a json string").Wrap(err)
+	}
+	return u.UnmarshalText([]byte(value))
+}
+
+// ULIDs are stored as bson strings, which keeps them sortable and readable
+func (u ULID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.TypeString, bsoncore.AppendString(nil, u.String()), nil
+}
+
+func (u *ULID) UnmarshalBSONValue(bsonType bsontype.Type, data []byte) error {
+	if bsonType != bson.TypeString {
+		return ErrInvalidID.WithMessage("cannot unmarshal bson " + bsonType.String() + " into ulid")
+	}
+	str, _, ok := bsoncore.ReadString(data)
+	if !ok {
+		return ErrInvalidID.WithMessage("invalid bson string")
+	}
+	return u.UnmarshalText([]byte(str))
+}
@@ -0,0 +1,99 @@
+package idgen
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+func TestParseULID(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"spec example", "01ARZ3NDEKTSV4RRFFQ69G5FAV", "01ARZ3NDEKTSV4RRFFQ69G5FAV", false},
+		{"lower case", "01arz3ndektsv4rrffq69g5fav", "01ARZ3NDEKTSV4RRFFQ69G5FAV", false},
+		{"aliases", "01ARZ3NDEKTSV4RRFFQ69G5FAo", "01ARZ3NDEKTSV4RRFFQ69G5FA0", false},
+		{"max", "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", false},
+		{"overflow", "8ZZZZZZZZZZZZZZZZZZZZZZZZZ", "", true},
+		{"invalid character", "01ARZ3NDEKTSV4RRFFQ69G5FAU", "", true},
+		{"short", "01ARZ3NDEKTSV4RRFFQ69G5FA", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ulid, err := ParseULID(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidID) {
+					t.Fatalf("ParseULID(%q) error = %v, want ErrInvalidID", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseULID(%q) error: %v", tt.value, err)
+			}
+			if got := ulid.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestULIDTime(t *testing.T) {
+	ulid, _ := ParseULID("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	if got, want := ulid.Time(), time.UnixMilli(1469922850259).UTC(); !got.Equal(want) {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+}
+
+func TestULIDGeneratorMonotonic(t *testing.T) {
+	fake := clock.NewFake(time.UnixMilli(1700000000000))
+	generator := NewULIDGenerator(fake)
+
+	previous := generator.New()
+	for i := 0; i < 1000; i++ {
+		ulid := generator.New()
+		if ulid.String() <= previous.String() {
+			t.Fatalf("ulid %d %s is not after %s", i, ulid, previous)
+		}
+		previous = ulid
+	}
+	fake.Advance(time.Millisecond)
+	if ulid := generator.New(); !ulid.Time().Equal(fake.Now().UTC()) {
+		t.Errorf("Time() = %v, want %v", ulid.Time(), fake.Now().UTC())
+	}
+}
+
+func TestULIDEncodings(t *testing.T) {
+	ulid := NewULID()
+
+	data, err := json.Marshal(ulid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"`+ulid.String()+`"` {
+		t.Errorf("json = %s, want the string form", data)
+	}
+	var fromJSON ULID
+	if err := json.Unmarshal(data, &fromJSON); err != nil || fromJSON != ulid {
+		t.Errorf("json round trip = %s, %v, want %s", fromJSON, err, ulid)
+	}
+
+	doc, err := bson.Marshal(bson.M{"id": ulid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bson.Raw(doc).Lookup("id").StringValue(); got != ulid.String() {
+		t.Errorf("bson = %q, want %q", got, ulid.String())
+	}
+	var fromBSON struct{ ID ULID }
+	if err := bson.Unmarshal(doc, &fromBSON); err != nil || fromBSON.ID != ulid {
+		t.Errorf("bson round trip = %s, %v, want %s", fromBSON.ID, err, ulid)
+	}
+}
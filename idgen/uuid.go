@@ -0,0 +1,189 @@
+package idgen
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+
+	"github.com/gnanasuryateja/golib/clock"
+)
+
+const (
+	uuid_version_7      = 7
+	uuid_counter_bits   = 12
+	uuid_counter_max    = 1<<uuid_counter_bits - 1
+	uuid_bson_subtype   = 0x04
+	uuid_string_length  = 36
+	uuid_compact_length = 32
+)
+
+// UUID is a RFC 9562 UUID, version 7 UUIDs are time ordered
+type UUID [16]byte
+
+// UUIDGenerator generates UUIDv7s which are monotonic within the same millisecond |
+// the 12 bit rand_a field is used as a counter seeded randomly every millisecond
+type UUIDGenerator struct {
+	clock   clock.Clock
+	lock    sync.Mutex
+	lastMs  int64
+	counter uint16
+}
+
+// creates a new UUIDGenerator, the real clock is used when c is nil
+func NewUUIDGenerator(c clock.Clock) *UUIDGenerator {
+	return &UUIDGenerator{
+		clock: clock.OrReal(c),
+	}
+}
+
+var defaultUUIDGenerator = NewUUIDGenerator(nil)
+
+// returns a new UUIDv7 from the default generator
+func NewUUIDv7() UUID {
+	return defaultUUIDGenerator.New()
+}
+
+// returns a new UUIDv7
+func (g *UUIDGenerator) New() UUID {
+	g.lock.Lock()
+	ms := g.clock.Now().UnixMilli()
+	if ms > g.lastMs {
+		// seed the counter with the top bit cleared to leave room for increments
+		g.lastMs = ms
+		g.counter = randomUint16() & (uuid_counter_max >> 1)
+	} else {
+		// same millisecond (or the clock went back), increment the counter and borrow the next millisecond on overflow
+		g.counter++
+		if g.counter > uuid_counter_max {
+			g.lastMs++
+			g.counter = 0
+		}
+	}
+	ms, counter := g.lastMs, g.counter
+	g.lock.Unlock()
+
+	var uuid UUID
+	uuid[0] = byte(ms >> 40)
+	uuid[1] = byte(ms >> 32)
+	uuid[2] = byte(ms >> 24)
+	uuid[3] = byte(ms >> 16)
+	uuid[4] = byte(ms >> 8)
+	uuid[5] = byte(ms)
+	uuid[6] = uuid_version_7<<4 | byte(counter>>8)&0x0F
+	uuid[7] = byte(counter)
+	randomBytes(uuid[8:])
+	uuid[8] = uuid[8]&0x3F | 0x80
+	return uuid
+}
+
+// parses the canonical (8-4-4-4-12) or the compact (32 hex digits) form
+func ParseUUID(value string) (UUID, error) {
+	var uuid UUID
+	compact := value
+	if len(value) == uuid_string_length {
+		if value[8] != '-' || value[13] != '-' || value[18] != '-' || value[23] != '-' {
+			return uuid, ErrInvalidID.WithMessage("invalid uuid " + value)
+		}
+		compact = strings.ReplaceAll(value, "-", "")
+	}
+	if len(compact) != uuid_compact_length {
+		return uuid, ErrInvalidID.WithMessage("invalid uuid length " + value)
+	}
+	_, err := hex.Decode(uuid[:], []byte(compact))
+	if err != nil {
+		return uuid, ErrInvalidID.WithMessage("invalid uuid " + value).Wrap(err)
+	}
+	return uuid, nil
+}
+
+// returns the version of the uuid
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// returns the timestamp of a UUIDv7 (zero time for the other versions)
+func (u UUID) Time() time.Time {
+	if u.Version() != uuid_version_7 {
+		return time.Time{}
+	}
+	ms := int64(u[0])<<40 | int64(u[1])<<32 | int64(u[2])<<24 | int64(u[3])<<16 | int64(u[4])<<8 | int64(u[5])
+	return time.UnixMilli(ms).UTC()
+}
+
+// reports whether the uuid is all zeros
+func (u UUID) IsZero() bool {
+	return u == UUID{}
+}
+
+// returns the canonical form
+func (u UUID) String() string {
+	var buf [uuid_string_length]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *UUID) UnmarshalText(text []byte) error {
+	parsed, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+func (u UUID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.String())
+}
+
+func (u *UUID) UnmarshalJSON(data []byte) error {
+	var value string
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return ErrInvalidID.WithMessage("uuid must be a json string").Wrap(err)
+	}
+	return u.UnmarshalText([]byte(value))
+}
+
+// uuids are stored as bson binary with the uuid subtype, which sorts by the bytes
+func (u UUID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.TypeBinary, bsoncore.AppendBinary(nil, uuid_bson_subtype, u[:]), nil
+}
+
+// binary and string values are accepted
+func (u *UUID) UnmarshalBSONValue(bsonType bsontype.Type, data []byte) error {
+	value := bsoncore.Value{Type: bsonType, Data: data}
+	switch bsonType {
+	case bson.TypeBinary:
+		_, bin, ok := value.BinaryOK()
+		if !ok || len(bin) != len(u) {
+			return ErrInvalidID.WithMessage("invalid bson uuid")
+		}
+		copy(u[:], bin)
+		return nil
+	case bson.TypeString:
+		str, ok := value.StringValueOK()
+		if !ok {
+			return ErrInvalidID.WithMessage("invalid bson string")
+		}
+		return u.UnmarshalText([]byte(str))
+	}
+	return ErrInvalidID.WithMessage("cannot unmarshal bson " + bsonType.String() + " into uuid")
+}
@@ -0,0 +1,113 @@
+package idgen
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+func TestParseUUID(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		version int
+		wantErr bool
+	}{
+		{"rfc 9562 v7 example", "017F22E2-79B0-7CC3-98C4-DC0C0C07398F", "017f22e2-79b0-7cc3-98c4-dc0c0c07398f", 7, false},
+		{"compact form", "017f22e279b07cc398c4dc0c0c07398f", "017f22e2-79b0-7cc3-98c4-dc0c0c07398f", 7, false},
+		{"v4", "919108f7-52d1-4320-9bac-f847db4148a8", "919108f7-52d1-4320-9bac-f847db4148a8", 4, false},
+		{"misplaced dashes", "017f22e279-b0-7cc3-98c4-dc0c0c07398f", "", 0, true},
+		{"short", "017f22e2-79b0-7cc3-98c4", "", 0, true},
+		{"not hex", "zzzzzzzz-79b0-7cc3-98c4-dc0c0c07398f", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uuid, err := ParseUUID(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidID) {
+					t.Fatalf("ParseUUID(%q) error = %v, want ErrInvalidID", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseUUID(%q) error: %v", tt.value, err)
+			}
+			if got := uuid.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if got := uuid.Version(); got != tt.version {
+				t.Errorf("Version() = %d, want %d", got, tt.version)
+			}
+		})
+	}
+}
+
+func TestUUIDTime(t *testing.T) {
+	uuid, _ := ParseUUID("017F22E2-79B0-7CC3-98C4-DC0C0C07398F")
+	if got, want := uuid.Time(), time.UnixMilli(1645557742000).UTC(); !got.Equal(want) {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+}
+
+func TestUUIDGeneratorMonotonic(t *testing.T) {
+	fake := clock.NewFake(time.UnixMilli(1700000000000))
+	generator := NewUUIDGenerator(fake)
+
+	// the clock does not move, the counter (and then the borrowed milliseconds) keep the uuids ordered
+	previous := generator.New()
+	for i := 0; i < 3*(uuid_counter_max+1); i++ {
+		uuid := generator.New()
+		if uuid.String() <= previous.String() {
+			t.Fatalf("uuid %d %s is not after %s", i, uuid, previous)
+		}
+		if uuid.Version() != uuid_version_7 || uuid[8]&0xC0 != 0x80 {
+			t.Fatalf("uuid %s has a wrong version or variant", uuid)
+		}
+		previous = uuid
+	}
+
+	// the clock going back does not break the order
+	fake.Set(time.UnixMilli(1600000000000))
+	if uuid := generator.New(); uuid.String() <= previous.String() {
+		t.Errorf("uuid %s is not after %s after the clock went back", uuid, previous)
+	}
+}
+
+func TestUUIDEncodings(t *testing.T) {
+	uuid := NewUUIDv7()
+
+	data, err := json.Marshal(uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON UUID
+	if err := json.Unmarshal(data, &fromJSON); err != nil || fromJSON != uuid {
+		t.Errorf("json round trip = %s, %v, want %s", fromJSON, err, uuid)
+	}
+	if err := json.Unmarshal([]byte("12"), &fromJSON); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("json number error = %v, want ErrInvalidID", err)
+	}
+
+	doc, err := bson.Marshal(bson.M{"id": uuid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := bson.Raw(doc).Lookup("id")
+	if subtype, _ := raw.Binary(); subtype != uuid_bson_subtype {
+		t.Errorf("bson subtype = %#x, want %#x", subtype, uuid_bson_subtype)
+	}
+	var fromBSON struct{ ID UUID }
+	if err := bson.Unmarshal(doc, &fromBSON); err != nil || fromBSON.ID != uuid {
+		t.Errorf("bson round trip = %s, %v, want %s", fromBSON.ID, err, uuid)
+	}
+	doc, _ = bson.Marshal(bson.M{"id": uuid.String()})
+	if err := bson.Unmarshal(doc, &fromBSON); err != nil || fromBSON.ID != uuid {
+		t.Errorf("bson string = %s, %v, want %s", fromBSON.ID, err, uuid)
+	}
+}
@@ -0,0 +1,220 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gnanasuryateja/golib/errors"
+	"github.com/gnanasuryateja/golib/logger"
+)
+
+const default_shutdown_timeout = 30 * time.Second
+
+// Closer is implemented by the components which hold resources (datastore clients, loggers, ...)
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+// CloserFunc adapts a function to the Closer interface
+type CloserFunc func(ctx context.Context) error
+
+func (cf CloserFunc) Close(ctx context.Context) error {
+	return cf(ctx)
+}
+
+// adapts an io.Closer (which does not take a ctx) to the Closer interface
+func FromIOCloser(closer io.Closer) Closer {
+	return CloserFunc(func(ctx context.Context) error {
+		return closer.Close()
+	})
+}
+
+type ManagerConfig struct {
+	ShutdownTimeout time.Duration // ShutdownTimeout bounds the whole shutdown, 30s by default |
+	Signals         []os.Signal   // Signals trigger the shutdown in Wait, SIGTERM and SIGINT by default |
+	Logger          logger.Logger // Logger logs the progress of the shutdown, nothing is logged when nil
+}
+
+// ShutdownError reports the components which failed to close
+type ShutdownError struct {
+	Failures map[string]error
+}
+
+func (se *ShutdownError) Error() string {
+	names := make([]string, 0, len(se.Failures))
+	for name := range se.Failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msgs := make([]string, 0, len(names))
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", name, se.Failures[name]))
+	}
+	return "failed to close " + strings.Join(msgs, "; ")
+}
+
+func (se *ShutdownError) Unwrap() []error {
+	errs := make([]error, 0, len(se.Failures))
+	for _, err := range se.Failures {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+type component struct {
+	name      string
+	closer    Closer
+	dependsOn []string
+}
+
+// Manager closes the registered components in the reverse dependency order on shutdown
+type Manager struct {
+	managerConfig ManagerConfig
+	lock          sync.Mutex
+	components    []component
+	shutdown      bool
+}
+
+// creates a new Manager
+func NewManager(managerConfig ManagerConfig) *Manager {
+	if managerConfig.ShutdownTimeout <= 0 {
+		managerConfig.ShutdownTimeout = default_shutdown_timeout
+	}
+	if len(managerConfig.Signals) == 0 {
+		managerConfig.Signals = []os.Signal{syscall.SIGTERM, syscall.SIGINT}
+	}
+	return &Manager{
+		managerConfig: managerConfig,
+	}
+}
+
+// registers the component, its dependencies must already be registered |
+// a component is closed only after all the components depending on it are closed
+func (m *Manager) Register(name string, closer Closer, dependsOn ...string) error {
+	if name == "" || closer == nil {
+		return errors.InvalidArgument("component cannot have empty name or closer")
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.shutdown {
+		return errors.FailedPrecondition("manager is already shut down")
+	}
+	registered := map[string]bool{}
+	for _, c := range m.components {
+		registered[c.name] = true
+	}
+	if registered[name] {
+		return errors.AlreadyExists(fmt.Sprintf("component %s is already registered", name))
+	}
+	for _, dependency := range dependsOn {
+		if !registered[dependency] {
+			return errors.InvalidArgument(fmt.Sprintf("dependency %s of %s is not registered", dependency, name))
+		}
+	}
+	m.components = append(m.components, component{
+		name:      name,
+		closer:    closer,
+		dependsOn: dependsOn,
+	})
+	return nil
+}
+
+// blocks until one of the signals is received or ctx is done and then shuts down
+func (m *Manager) Wait(ctx context.Context) error {
+	signalCtx, stop := signal.NotifyContext(ctx, m.managerConfig.Signals...)
+	defer stop()
+	<-signalCtx.Done()
+	m.log(context.Background(), "shutdown triggered")
+	return m.Shutdown(context.WithoutCancel(ctx))
+}
+
+// closes the components within the shutdown timeout, independent components are closed concurrently |
+// the components which failed (or did not finish) are reported in a ShutdownError
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.lock.Lock()
+	if m.shutdown {
+		m.lock.Unlock()
+		return nil
+	}
+	m.shutdown = true
+	components := m.components
+	m.lock.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, m.managerConfig.ShutdownTimeout)
+	defer cancel()
+
+	// find the dependents of every component
+	done := make(map[string]chan struct{}, len(components))
+	dependents := make(map[string][]string, len(components))
+	for _, c := range components {
+		done[c.name] = make(chan struct{})
+		for _, dependency := range c.dependsOn {
+			dependents[dependency] = append(dependents[dependency], c.name)
+		}
+	}
+
+	// close every component once its dependents are closed
+	var failuresLock sync.Mutex
+	failures := map[string]error{}
+	var wg sync.WaitGroup
+	for _, c := range components {
+		wg.Add(1)
+		go func(c component) {
+			defer wg.Done()
+			defer close(done[c.name])
+
+			err := m.closeComponent(ctx, c, dependents[c.name], done)
+			if err != nil {
+				failuresLock.Lock()
+				failures[c.name] = err
+				failuresLock.Unlock()
+				m.log(ctx, fmt.Sprintf("failed to close %s: %v", c.name, err))
+				return
+			}
+			m.log(ctx, "closed "+c.name)
+		}(c)
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return &ShutdownError{Failures: failures}
+	}
+	return nil
+}
+
+// waits for the dependents and closes the component, gives up when ctx is done
+func (m *Manager) closeComponent(ctx context.Context, c component, dependents []string, done map[string]chan struct{}) error {
+	for _, dependent := range dependents {
+		select {
+		case <-done[dependent]:
+		case <-ctx.Done():
+			return fmt.Errorf("not closed while waiting for %s: %w", dependent, ctx.Err())
+		}
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- c.closer.Close(ctx)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("close did not finish: %w", ctx.Err())
+	}
+}
+
+func (m *Manager) log(ctx context.Context, message string) {
+	if m.managerConfig.Logger != nil {
+		m.managerConfig.Logger.Info(ctx, message)
+	}
+}
@@ -0,0 +1,325 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/datastore"
+	"github.com/gnanasuryateja/golib/datastore/cache"
+	redisstore "github.com/gnanasuryateja/golib/datastore/cache/redis"
+	"github.com/gnanasuryateja/golib/errors"
+	"github.com/gnanasuryateja/golib/idgen"
+	"github.com/gnanasuryateja/golib/validation"
+)
+
+const (
+	default_ttl            = 30 * time.Second
+	default_retry_interval = 100 * time.Millisecond
+	default_key_prefix     = "lock:"
+	fencing_key_suffix     = ":fencing"
+)
+
+var (
+	ErrNotAcquired = errors.Conflict("lock is held by another owner")
+	ErrNotHeld     = errors.FailedPrecondition("lock is not held")
+	ErrLost        = errors.FailedPrecondition("lock was lost")
+)
+
+// acquire sets the lock only when it is free and returns the next fencing token, 0 when the lock is held
+var acquireScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0
+`)
+
+// release deletes the lock only when it is still owned
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// extend resets the ttl of the lock only when it is still owned
+var extendScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+type Config struct {
+	TTL           time.Duration                `validate:"min=0"` // TTL is the expiry of the lock in redis, 30s by default |
+	RenewInterval time.Duration                `validate:"min=0"` // RenewInterval is the period of the automatic renewal, TTL/3 by default |
+	DisableRenew  bool                         // DisableRenew turns off the automatic renewal, the lock then expires after TTL unless extended |
+	RetryInterval time.Duration                `validate:"min=0"` // RetryInterval is the wait between the attempts of Acquire, 100ms by default |
+	KeyPrefix     string                       // KeyPrefix is prepended to the lock names, lock: by default |
+	OnLost        func(name string, err error) // OnLost is called when a held lock is lost (expired or taken over) |
+	Clock         clock.Clock                  `validate:"-"` // Clock drives the renewal and the retries, the real clock by default
+}
+
+// validates the input params, the durations are compared with the defaults filled in
+func (c Config) validate() error {
+	err := validation.Validate(c)
+	if err != nil {
+		return err
+	}
+
+	// redis rejects PX 0, the ttl is sent in milliseconds
+	if c.TTL < time.Millisecond {
+		return errors.InvalidArgument("TTL must be at least 1ms")
+	}
+	if c.RenewInterval >= c.TTL {
+		return errors.InvalidArgument("RenewInterval must be shorter than TTL")
+	}
+	return nil
+}
+
+// returns the config with the defaults filled in
+func (c Config) withDefaults() Config {
+	if c.TTL == 0 {
+		c.TTL = default_ttl
+	}
+	if c.RenewInterval == 0 {
+		c.RenewInterval = c.TTL / 3
+	}
+	if c.RetryInterval == 0 {
+		c.RetryInterval = default_retry_interval
+	}
+	if c.KeyPrefix == "" {
+		c.KeyPrefix = default_key_prefix
+	}
+	c.Clock = clock.OrReal(c.Clock)
+	return c
+}
+
+// Locker grants distributed locks stored in redis
+type Locker struct {
+	config Config
+	client redis.Scripter
+}
+
+// creates a new Locker on the redis cache created by redis.NewRedisStoreClient
+func NewLocker(c cache.Cache, config Config) (*Locker, error) {
+
+	// validate the config
+	config = config.withDefaults()
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	// get the go-redis client of the cache
+	client, err := redisstore.GetRedisClient(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Locker{
+		config: config,
+		client: client,
+	}, nil
+}
+
+// acquires the lock once, ErrNotAcquired is returned when it is held by another owner
+func (l *Locker) TryAcquire(ctx context.Context, name string) (*Lock, error) {
+
+	// validate the passed args
+	if name == "" {
+		return nil, datastore.ErrInvalidArgs.WithMessage("lock name cannot be empty")
+	}
+
+	// the hash tag keeps the lock and its fencing counter on the same cluster slot
+	key := l.config.KeyPrefix + "{" + name + "}"
+	value := idgen.NewULID().String()
+	token, err := acquireScript.Run(ctx, l.client, []string{key, key + fencing_key_suffix}, value, l.config.TTL.Milliseconds()).Int64()
+	if err != nil {
+		return nil, redisstore.WrapError(err, "failed to acquire the lock "+name)
+	}
+	if token == 0 {
+		return nil, ErrNotAcquired.WithMessage("lock " + name + " is held by another owner")
+	}
+
+	lock := &Lock{
+		locker:  l,
+		name:    name,
+		key:     key,
+		value:   value,
+		token:   token,
+		expires: l.config.Clock.Now().Add(l.config.TTL),
+		lost:    make(chan struct{}),
+		stop:    make(chan struct{}),
+	}
+	if !l.config.DisableRenew {
+		lock.done.Add(1)
+		go lock.renew()
+	}
+	return lock, nil
+}
+
+// acquires the lock, waiting RetryInterval between the attempts until ctx is done
+func (l *Locker) Acquire(ctx context.Context, name string) (*Lock, error) {
+	for {
+		lock, err := l.TryAcquire(ctx, name)
+		if !errors.Is(err, ErrNotAcquired) {
+			return lock, err
+		}
+
+		// wait before the next attempt
+		timer := l.config.Clock.NewTimer(l.config.RetryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(err, ctx.Err())
+		case <-timer.C():
+		}
+	}
+}
+
+// Lock is a lock held in redis
+type Lock struct {
+	locker   *Locker
+	name     string
+	key      string
+	value    string
+	token    int64
+	lock     sync.Mutex
+	expires  time.Time
+	released bool
+	lostErr  error
+	lost     chan struct{}
+	stop     chan struct{}
+	done     sync.WaitGroup
+}
+
+// returns the name of the lock
+func (l *Lock) Name() string {
+	return l.name
+}
+
+// returns the fencing token of the grant, it increases with every grant of the lock name |
+// writes guarded by the lock should be rejected when they carry an older token, see mongofence.Filter
+func (l *Lock) Token() int64 {
+	return l.token
+}
+
+// is closed when the lock is lost, it is not closed by Release
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// returns the error the lock was lost with, nil while held
+func (l *Lock) Err() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.lostErr
+}
+
+// resets the ttl of the lock, ErrNotHeld is returned when the lock expired or was taken over |
+// the ttl must be at least a millisecond, PEXPIRE would delete the lock otherwise
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+
+	// validate the passed args
+	if ttl < time.Millisecond {
+		return datastore.ErrInvalidArgs.WithMessage("lock ttl must be at least 1ms")
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.released || l.lostErr != nil {
+		return ErrNotHeld.WithMessage("lock " + l.name + " is not held")
+	}
+	return l.extend(ctx, ttl)
+}
+
+// extends the lock, the caller holds l.lock
+func (l *Lock) extend(ctx context.Context, ttl time.Duration) error {
+	extended, err := extendScript.Run(ctx, l.locker.client, []string{l.key}, l.value, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return redisstore.WrapError(err, "failed to extend the lock "+l.name)
+	}
+	if extended == 0 {
+		l.markLost(ErrLost.WithMessage("lock " + l.name + " expired or was taken over"))
+		return ErrNotHeld.WithMessage("lock " + l.name + " is not held")
+	}
+	l.expires = l.locker.config.Clock.Now().Add(ttl)
+	return nil
+}
+
+// releases the lock and stops the renewal, calling it more than once is a no-op
+func (l *Lock) Release(ctx context.Context) error {
+	l.lock.Lock()
+	if l.released {
+		l.lock.Unlock()
+		return nil
+	}
+	l.released = true
+	close(l.stop)
+	lostErr := l.lostErr
+	l.lock.Unlock()
+
+	// wait for the renewal to stop
+	l.done.Wait()
+	if lostErr != nil {
+		return ErrNotHeld.WithMessage("lock " + l.name + " was lost before the release").Wrap(lostErr)
+	}
+
+	released, err := releaseScript.Run(ctx, l.locker.client, []string{l.key}, l.value).Int64()
+	if err != nil {
+		return redisstore.WrapError(err, "failed to release the lock "+l.name)
+	}
+	if released == 0 {
+		return ErrNotHeld.WithMessage("lock " + l.name + " expired before the release")
+	}
+	return nil
+}
+
+// renews the lock every RenewInterval until it is released or lost |
+// failed renewals are retried until the ttl runs out
+func (l *Lock) renew() {
+	defer l.done.Done()
+	config := l.locker.config
+	ticker := config.Clock.NewTicker(config.RenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C():
+		}
+
+		l.lock.Lock()
+		if l.released {
+			l.lock.Unlock()
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), config.RenewInterval)
+		err := l.extend(ctx, config.TTL)
+		cancel()
+		if err != nil && l.lostErr == nil && !config.Clock.Now().Before(l.expires) {
+			l.markLost(ErrLost.WithMessage("lock " + l.name + " expired while the renewal failed").Wrap(err))
+		}
+		lost := l.lostErr != nil
+		l.lock.Unlock()
+		if lost {
+			return
+		}
+	}
+}
+
+// marks the lock as lost and notifies the listeners once, the caller holds l.lock
+func (l *Lock) markLost(err error) {
+	if l.lostErr != nil {
+		return
+	}
+	l.lostErr = err
+	close(l.lost)
+	if l.locker.config.OnLost != nil {
+		go l.locker.config.OnLost(l.name, err)
+	}
+}
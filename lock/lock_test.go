@@ -0,0 +1,210 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/datastore"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+// fakeScripter answers the scripts of the package without redis, extend is replaced by the tests
+type fakeScripter struct {
+	lock    sync.Mutex
+	token   int64
+	extend  func() (int64, error)
+	extends chan struct{}
+}
+
+func newFakeScripter() *fakeScripter {
+	return &fakeScripter{
+		extend:  func() (int64, error) { return 1, nil },
+		extends: make(chan struct{}, 10),
+	}
+}
+
+func (fs *fakeScripter) EvalSha(ctx context.Context, sha1 string, keys []string, args ...any) *redis.Cmd {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	cmd := redis.NewCmd(ctx)
+	switch sha1 {
+	case acquireScript.Hash():
+		fs.token++
+		cmd.SetVal(fs.token)
+	case extendScript.Hash():
+		value, err := fs.extend()
+		cmd.SetVal(value)
+		cmd.SetErr(err)
+		fs.extends <- struct{}{}
+	case releaseScript.Hash():
+		cmd.SetVal(int64(1))
+	}
+	return cmd
+}
+
+func (fs *fakeScripter) Eval(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+	panic("EvalSha is always answered")
+}
+
+func (fs *fakeScripter) EvalRO(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+	panic("not used")
+}
+
+func (fs *fakeScripter) EvalShaRO(ctx context.Context, sha1 string, keys []string, args ...any) *redis.Cmd {
+	panic("not used")
+}
+
+func (fs *fakeScripter) ScriptExists(ctx context.Context, hashes ...string) *redis.BoolSliceCmd {
+	panic("not used")
+}
+
+func (fs *fakeScripter) ScriptLoad(ctx context.Context, script string) *redis.StringCmd {
+	panic("not used")
+}
+
+// sets the extend answer of the scripter
+func (fs *fakeScripter) setExtend(extend func() (int64, error)) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	fs.extend = extend
+}
+
+func newTestLocker(t *testing.T, config Config) (*Locker, *fakeScripter) {
+	t.Helper()
+	config = config.withDefaults()
+	if err := config.validate(); err != nil {
+		t.Fatal(err)
+	}
+	scripter := newFakeScripter()
+	return &Locker{config: config, client: scripter}, scripter
+}
+
+func TestConfigDefaultsAndValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"defaults", Config{}, false},
+		{"renew interval longer than the default ttl", Config{RenewInterval: 45 * time.Second}, true},
+		{"renew interval equal to the ttl", Config{TTL: time.Second, RenewInterval: time.Second}, true},
+		{"ttl under a millisecond", Config{TTL: time.Microsecond, RenewInterval: time.Nanosecond}, true},
+		{"negative ttl", Config{TTL: -time.Second}, true},
+		{"custom durations", Config{TTL: 10 * time.Second, RenewInterval: time.Second}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.withDefaults().validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	config := Config{}.withDefaults()
+	if config.TTL != default_ttl || config.RenewInterval != default_ttl/3 || config.RetryInterval != default_retry_interval || config.KeyPrefix != default_key_prefix {
+		t.Errorf("withDefaults() = %+v", config)
+	}
+}
+
+func TestFencingTokensIncrease(t *testing.T) {
+	locker, _ := newTestLocker(t, Config{DisableRenew: true})
+	ctx := context.Background()
+	var last int64
+	for i := 0; i < 3; i++ {
+		lock, err := locker.TryAcquire(ctx, "job")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lock.Token() <= last {
+			t.Fatalf("Token() = %d, want more than %d", lock.Token(), last)
+		}
+		last = lock.Token()
+		if err := lock.Release(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExtendRejectsShortTTL(t *testing.T) {
+	locker, _ := newTestLocker(t, Config{DisableRenew: true})
+	lock, err := locker.TryAcquire(context.Background(), "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Extend(context.Background(), 0); !errors.Is(err, datastore.ErrInvalidArgs) {
+		t.Fatalf("Extend(0) error = %v, want ErrInvalidArgs", err)
+	}
+}
+
+func TestRenewLosesTakenOverLock(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	lostNames := make(chan string, 1)
+	locker, scripter := newTestLocker(t, Config{
+		Clock:  fake,
+		OnLost: func(name string, err error) { lostNames <- name },
+	})
+	lock, err := locker.TryAcquire(context.Background(), "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.BlockUntil(1)
+
+	// the first renewal succeeds
+	fake.Advance(locker.config.RenewInterval)
+	<-scripter.extends
+	if lock.Err() != nil {
+		t.Fatalf("Err() = %v after a renewal", lock.Err())
+	}
+
+	// the lock was taken over
+	scripter.setExtend(func() (int64, error) { return 0, nil })
+	fake.Advance(locker.config.RenewInterval)
+	<-scripter.extends
+	select {
+	case <-lock.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("Lost() was not closed")
+	}
+	if name := <-lostNames; name != "job" {
+		t.Errorf("OnLost name = %s, want job", name)
+	}
+	if !errors.Is(lock.Err(), ErrLost) {
+		t.Errorf("Err() = %v, want ErrLost", lock.Err())
+	}
+	if err := lock.Release(context.Background()); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("Release() error = %v, want ErrNotHeld", err)
+	}
+}
+
+func TestRenewFailuresLoseLockAfterTTL(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	locker, scripter := newTestLocker(t, Config{TTL: 30 * time.Second, Clock: fake})
+	scripter.setExtend(func() (int64, error) { return 0, errors.Unavailable("connection refused") })
+	lock, err := locker.TryAcquire(context.Background(), "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.BlockUntil(1)
+
+	// the failed renewals keep the lock until its ttl passed
+	for i := 0; i < 2; i++ {
+		fake.Advance(10 * time.Second)
+		<-scripter.extends
+		if lock.Err() != nil {
+			t.Fatalf("Err() = %v before the ttl passed", lock.Err())
+		}
+	}
+	fake.Advance(10 * time.Second)
+	<-scripter.extends
+	select {
+	case <-lock.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("Lost() was not closed after the ttl passed")
+	}
+}
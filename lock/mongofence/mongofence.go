@@ -0,0 +1,16 @@
+package mongofence
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// returns a mongo filter matching the documents written with the same or an older fencing token (or none) |
+// combine it with the filter of the update and $set the field to lock.Token() so that stale lock holders are rejected
+func Filter(field string, token int64) bson.M {
+	return bson.M{
+		"$or": bson.A{
+			bson.M{field: bson.M{"$lte": token}},
+			bson.M{field: bson.M{"$exists": false}},
+		},
+	}
+}
@@ -5,6 +5,7 @@ import (
 )
 
 type Logger interface {
+	Close(ctx context.Context) error
 	Debug(ctx context.Context, message string)
 	Error(ctx context.Context, err error)
 	Info(ctx context.Context, message string)
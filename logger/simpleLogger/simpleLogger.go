@@ -3,40 +3,34 @@ package simplelogger
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/gnanasuryateja/golib/clock"
 	"github.com/gnanasuryateja/golib/constants"
 	"github.com/gnanasuryateja/golib/logger"
+	"github.com/gnanasuryateja/golib/validation"
 )
 
 type SimpleLoggerParams struct {
-	ServiceName          string  // ServiceName is the name of the service in which you are working |
-	LogLevel             *string // LogLevel is the log level configured from env |
-	SkipLevelForFuncInfo *int    // SkipLevelForFuncInfo refers to the skip param to pass in runtime.Caller(skip)
-	Env                  string  // Env is the environment in which the application is running
+	ServiceName          string      `config:"service_name,required"`    // ServiceName is the name of the service in which you are working |
+	LogLevel             *string     `config:"log_level"`                // LogLevel is the log level configured from env |
+	SkipLevelForFuncInfo *int        `config:"skip_level_for_func_info"` // SkipLevelForFuncInfo refers to the skip param to pass in runtime.Caller(skip)
+	Env                  string      `config:"env"`                      // Env is the environment in which the application is running |
+	Clock                clock.Clock `config:"-"`                        // Clock stamps the logs, the real clock is used when nil
 }
 
 type simpleLogger struct {
-	ServiceName          string // ServiceName is the name of the service in which you are working |
-	LogLevel             string // LogLevel is the log level configured from env |
-	SkipLevelForFuncInfo int    // SkipLevelForFuncInfo refers to the skip param to pass in runtime.Caller(skip)
-	Env                  string // Env is the environment in which the application is running
+	ServiceName          string      `validate:"required"`                                // ServiceName is the name of the service in which you are working |
+	LogLevel             string      `validate:"omitempty,oneofci=DEBUG ERROR INFO WARN"` // LogLevel is the log level configured from env |
+	SkipLevelForFuncInfo int         // SkipLevelForFuncInfo refers to the skip param to pass in runtime.Caller(skip)
+	Env                  string      // Env is the environment in which the application is running |
+	Clock                clock.Clock `validate:"-"` // Clock stamps the logs
 }
 
 func (sl simpleLogger) validate() error {
-	if sl.ServiceName == "" {
-		return fmt.Errorf("service name is passed as empty")
-	}
-	if sl.LogLevel != "" {
-		if !(strings.EqualFold(sl.LogLevel, constants.LOG_LEVEL_DEBUG) ||
-			strings.EqualFold(sl.LogLevel, constants.LOG_LEVEL_ERROR) ||
-			strings.EqualFold(sl.LogLevel, constants.LOG_LEVEL_INFO) ||
-			strings.EqualFold(sl.LogLevel, constants.LOG_LEVEL_WARN)) {
-			return fmt.Errorf("invalid log level... %s is not supported by simpleLogger", sl.LogLevel)
-		}
-	}
-	return nil
+	return validation.Validate(sl)
 }
 
 func NewSimpleLogger(loggerParams SimpleLoggerParams) (logger.Logger, error) {
@@ -53,6 +47,7 @@ func NewSimpleLogger(loggerParams SimpleLoggerParams) (logger.Logger, error) {
 		simpleLogger.SkipLevelForFuncInfo = *loggerParams.SkipLevelForFuncInfo
 	}
 	simpleLogger.Env = loggerParams.Env
+	simpleLogger.Clock = clock.OrReal(loggerParams.Clock)
 	err := simpleLogger.validate()
 	if err != nil {
 		return nil, err
@@ -60,12 +55,18 @@ func NewSimpleLogger(loggerParams SimpleLoggerParams) (logger.Logger, error) {
 	return simpleLogger, nil
 }
 
+// flushes stdout, stdout which cannot be synced (terminals, pipes) is ignored
+func (sl simpleLogger) Close(ctx context.Context) error {
+	_ = os.Stdout.Sync()
+	return nil
+}
+
 // Debug logs would be printed only when LogLevel is set to DEBUG |
 func (sl simpleLogger) Debug(ctx context.Context, message string) {
 	funcName, fileName, lineNo := logger.GetCurrentFuncInfo(sl.SkipLevelForFuncInfo)
 	if strings.EqualFold(sl.LogLevel, "DEBUG") {
 		fServiceName := fmt.Sprintf("[%v]", sl.ServiceName)
-		fmt.Println(buildSimpleLog(fServiceName, "debug", funcName, fileName, lineNo, message))
+		fmt.Println(buildSimpleLog(sl.Clock.Now(), fServiceName, "debug", funcName, fileName, lineNo, message))
 	}
 }
 
@@ -73,14 +74,14 @@ func (sl simpleLogger) Debug(ctx context.Context, message string) {
 func (sl simpleLogger) Error(ctx context.Context, err error) {
 	funcName, fileName, lineNo := logger.GetCurrentFuncInfo(sl.SkipLevelForFuncInfo)
 	fServiceName := fmt.Sprintf("[%v]", sl.ServiceName)
-	fmt.Println(buildSimpleLog(fServiceName, "error", funcName, fileName, lineNo, err.Error()))
+	fmt.Println(buildSimpleLog(sl.Clock.Now(), fServiceName, "error", funcName, fileName, lineNo, err.Error()))
 }
 
 // Info logs would always be printed |
 func (sl simpleLogger) Info(ctx context.Context, message string) {
 	funcName, fileName, lineNo := logger.GetCurrentFuncInfo(sl.SkipLevelForFuncInfo)
 	fServiceName := fmt.Sprintf("[%v]", sl.ServiceName)
-	fmt.Println(buildSimpleLog(fServiceName, "info", funcName, fileName, lineNo, message))
+	fmt.Println(buildSimpleLog(sl.Clock.Now(), fServiceName, "info", funcName, fileName, lineNo, message))
 }
 
 // Warn logs would be printed only when LogLevel is set to DEBUG |
@@ -88,10 +89,10 @@ func (sl simpleLogger) Warn(ctx context.Context, message string) {
 	funcName, fileName, lineNo := logger.GetCurrentFuncInfo(sl.SkipLevelForFuncInfo)
 	if strings.EqualFold(sl.LogLevel, "DEBUG") {
 		fServiceName := fmt.Sprintf("[%v]", sl.ServiceName)
-		fmt.Println(buildSimpleLog(fServiceName, "warn", funcName, fileName, lineNo, message))
+		fmt.Println(buildSimpleLog(sl.Clock.Now(), fServiceName, "warn", funcName, fileName, lineNo, message))
 	}
 }
 
-func buildSimpleLog(serviceName string, logFunc string, funcName string, fileName string, lineNo int, logMsg string) string {
-	return serviceName + " [" + time.Now().UTC().Format(constants.SIMPLE_LOGGER_TIME_FORMAT) + "] " + strings.ToUpper(logFunc) + ": " + funcName + "() " + fileName + fmt.Sprintf(":%v ", lineNo) + logMsg
+func buildSimpleLog(timestamp time.Time, serviceName string, logFunc string, funcName string, fileName string, lineNo int, logMsg string) string {
+	return serviceName + " [" + timestamp.UTC().Format(constants.SIMPLE_LOGGER_TIME_FORMAT) + "] " + strings.ToUpper(logFunc) + ": " + funcName + "() " + fileName + fmt.Sprintf(":%v ", lineNo) + logMsg
 }
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gnanasuryateja/golib/errors"
+	"github.com/gnanasuryateja/golib/validation"
+)
+
+const (
+	HEADER_RETRY_AFTER          = "Retry-After"
+	HEADER_RATE_LIMIT_LIMIT     = "X-RateLimit-Limit"
+	HEADER_RATE_LIMIT_REMAINING = "X-RateLimit-Remaining"
+	HEADER_RATE_LIMIT_RESET     = "X-RateLimit-Reset"
+)
+
+type MiddlewareConfig struct {
+	Limiter  *Limiter                     `validate:"required"` // Limiter limits the requests |
+	KeyFunc  func(r *http.Request) string // KeyFunc returns the key of the request (e.g. the tenant), the remote ip by default |
+	FailOpen bool                         // FailOpen lets the requests through when the limiter fails, they get the error as a problem otherwise |
+	Mapper   *errors.Mapper               `validate:"-"` // Mapper writes the problem responses, errors.NewMapper with the defaults when nil
+}
+
+// validates the input params
+func (mc MiddlewareConfig) validate() error {
+	return validation.Validate(mc)
+}
+
+// creates a middleware which limits the requests per key |
+// the X-RateLimit-* headers are set on every response, denied requests get 429 with Retry-After in seconds
+func NewMiddleware(middlewareConfig MiddlewareConfig) (func(http.Handler) http.Handler, error) {
+
+	// validate the middlewareConfig
+	err := middlewareConfig.validate()
+	if err != nil {
+		return nil, err
+	}
+	if middlewareConfig.KeyFunc == nil {
+		middlewareConfig.KeyFunc = RemoteIP
+	}
+	if middlewareConfig.Mapper == nil {
+		middlewareConfig.Mapper = errors.NewMapper(errors.MapperConfig{})
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := middlewareConfig.Limiter.Allow(r.Context(), middlewareConfig.KeyFunc(r))
+			if err != nil {
+				if middlewareConfig.FailOpen {
+					next.ServeHTTP(w, r)
+					return
+				}
+				middlewareConfig.Mapper.WriteProblem(w, r, err)
+				return
+			}
+
+			// set the rate limit headers
+			w.Header().Set(HEADER_RATE_LIMIT_LIMIT, strconv.Itoa(result.Limit))
+			w.Header().Set(HEADER_RATE_LIMIT_REMAINING, strconv.Itoa(result.Remaining))
+			w.Header().Set(HEADER_RATE_LIMIT_RESET, formatSeconds(result.ResetAfter))
+			if !result.Allowed {
+				w.Header().Set(HEADER_RETRY_AFTER, formatSeconds(result.RetryAfter))
+				middlewareConfig.Mapper.WriteProblem(w, r, ErrRateLimited.WithMetadata(metadata_retry_key, result.RetryAfter.Milliseconds()))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// returns the ip of the client from the remote address of the request
+func RemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// formats the duration as whole seconds rounded up
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,212 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gnanasuryateja/golib/constants"
+)
+
+const min_sweep_interval = time.Minute
+
+type localState struct {
+	tat       time.Time   // tat is the theoretical arrival time of gcra |
+	tokens    float64     // tokens is the content of the token bucket at last |
+	last      time.Time   // last is the time tokens was computed |
+	log       []time.Time // log has the sorted timestamps of the sliding window log |
+	expiresAt time.Time   // expiresAt is the time after which the state equals a fresh one and can be dropped
+}
+
+type localLimiter struct {
+	config    Config
+	lock      sync.Mutex
+	states    map[string]*localState
+	lastSweep time.Time
+}
+
+// creates a new in-process Limiter, the limits hold only within a single instance
+func NewLocalLimiter(config Config) (*Limiter, error) {
+
+	// validate the config
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+	config = config.withDefaults()
+
+	return &Limiter{
+		config: config,
+		backend: &localLimiter{
+			config:    config,
+			states:    map[string]*localState{},
+			lastSweep: config.Clock.Now(),
+		},
+	}, nil
+}
+
+func (ll *localLimiter) take(ctx context.Context, key string, n int, maxWait time.Duration) (Result, error) {
+	ll.lock.Lock()
+	defer ll.lock.Unlock()
+
+	now := ll.config.Clock.Now()
+	ll.sweep(now)
+	state, ok := ll.states[key]
+	if !ok {
+		state = &localState{}
+		ll.states[key] = state
+	}
+
+	switch ll.config.Algorithm {
+	case constants.RATE_LIMIT_ALGORITHM_TOKEN_BUCKET:
+		return ll.tokenBucket(state, now, n, maxWait), nil
+	case constants.RATE_LIMIT_ALGORITHM_SLIDING_WINDOW_LOG:
+		return ll.slidingWindowLog(state, now, n, maxWait), nil
+	}
+	return ll.gcra(state, now, n, maxWait), nil
+}
+
+// drops the expired states, at most once per period (or minute)
+func (ll *localLimiter) sweep(now time.Time) {
+	interval := ll.config.Period
+	if interval < min_sweep_interval {
+		interval = min_sweep_interval
+	}
+	if now.Sub(ll.lastSweep) < interval {
+		return
+	}
+	ll.lastSweep = now
+	for key, state := range ll.states {
+		if !state.expiresAt.After(now) {
+			delete(ll.states, key)
+		}
+	}
+}
+
+// generic cell rate algorithm, every request moves the theoretical arrival time by the emission interval
+func (ll *localLimiter) gcra(state *localState, now time.Time, n int, maxWait time.Duration) Result {
+	emission := ll.config.emissionInterval()
+	burstOffset := emission * time.Duration(ll.config.Burst)
+
+	tat := state.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	newTat := tat.Add(emission * time.Duration(n))
+	delay := newTat.Add(-burstOffset).Sub(now)
+	if delay > maxWait {
+		return Result{
+			Limit:      ll.config.Burst,
+			Remaining:  remainingOf(burstOffset-tat.Sub(now), emission),
+			RetryAfter: delay,
+			ResetAfter: tat.Sub(now),
+		}
+	}
+
+	state.tat = newTat
+	state.expiresAt = newTat
+	return Result{
+		Allowed:    true,
+		Limit:      ll.config.Burst,
+		Remaining:  remainingOf(burstOffset-newTat.Sub(now), emission),
+		Delay:      max(delay, 0),
+		ResetAfter: newTat.Sub(now),
+	}
+}
+
+// token bucket of Burst tokens refilled at Limit per Period, reservations may take the bucket below zero
+func (ll *localLimiter) tokenBucket(state *localState, now time.Time, n int, maxWait time.Duration) Result {
+	capacity := float64(ll.config.Burst)
+	perNanosecond := float64(ll.config.Limit) / float64(ll.config.Period)
+
+	// refill the bucket
+	if state.last.IsZero() {
+		state.tokens = capacity
+		state.last = now
+	}
+	if now.After(state.last) {
+		state.tokens = math.Min(capacity, state.tokens+float64(now.Sub(state.last))*perNanosecond)
+		state.last = now
+	}
+
+	var delay time.Duration
+	if state.tokens < float64(n) {
+		delay = time.Duration(math.Ceil((float64(n) - state.tokens) / perNanosecond))
+	}
+	if delay > maxWait {
+		return Result{
+			Limit:      ll.config.Burst,
+			Remaining:  int(math.Max(state.tokens, 0)),
+			RetryAfter: delay,
+			ResetAfter: time.Duration(math.Ceil((capacity - state.tokens) / perNanosecond)),
+		}
+	}
+
+	state.tokens -= float64(n)
+	resetAfter := time.Duration(math.Ceil((capacity - state.tokens) / perNanosecond))
+	state.expiresAt = now.Add(resetAfter)
+	return Result{
+		Allowed:    true,
+		Limit:      ll.config.Burst,
+		Remaining:  int(math.Max(state.tokens, 0)),
+		Delay:      delay,
+		ResetAfter: resetAfter,
+	}
+}
+
+// sliding window log, the requests of the last Period are kept and at most Limit of them are allowed
+func (ll *localLimiter) slidingWindowLog(state *localState, now time.Time, n int, maxWait time.Duration) Result {
+	period := ll.config.Period
+
+	// drop the requests which left the window
+	cutoff := now.Add(-period)
+	expired := sort.Search(len(state.log), func(i int) bool {
+		return state.log[i].After(cutoff)
+	})
+	state.log = state.log[expired:]
+
+	// wait for the oldest requests to leave the window
+	var delay time.Duration
+	count := len(state.log)
+	if count+n > ll.config.Limit {
+		delay = state.log[count+n-ll.config.Limit-1].Add(period).Sub(now)
+	}
+	if delay > maxWait {
+		return Result{
+			Limit:      ll.config.Limit,
+			Remaining:  max(ll.config.Limit-count, 0),
+			RetryAfter: delay,
+			ResetAfter: state.log[count-1].Add(period).Sub(now),
+		}
+	}
+
+	// log the requests, reservations are logged at the time they are allowed
+	at := now.Add(delay)
+	position := sort.Search(len(state.log), func(i int) bool {
+		return state.log[i].After(at)
+	})
+	entries := make([]time.Time, n)
+	for i := range entries {
+		entries[i] = at
+	}
+	state.log = append(state.log[:position], append(entries, state.log[position:]...)...)
+	last := state.log[len(state.log)-1].Add(period)
+	state.expiresAt = last
+	return Result{
+		Allowed:    true,
+		Limit:      ll.config.Limit,
+		Remaining:  max(ll.config.Limit-len(state.log), 0),
+		Delay:      delay,
+		ResetAfter: last.Sub(now),
+	}
+}
+
+// returns the number of requests fitting in the free part of the burst
+func remainingOf(free time.Duration, emission time.Duration) int {
+	if free <= 0 {
+		return 0
+	}
+	return int(free / emission)
+}
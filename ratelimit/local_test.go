@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"valid", Config{Limit: 100, Period: time.Second}, false},
+		{"zero limit", Config{Limit: 0, Period: time.Second}, true},
+		{"short period", Config{Limit: 1, Period: time.Microsecond}, true},
+		{"unknown algorithm", Config{Algorithm: "leaky", Limit: 1, Period: time.Second}, true},
+		{"emission below a microsecond", Config{Limit: 2000, Period: time.Millisecond}, true},
+		{"emission of a microsecond", Config{Limit: 1000, Period: time.Millisecond}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLocalLimiter(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewLocalLimiter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLocalLimiter(t *testing.T) {
+	// every algorithm allows 2 requests per second, the third one waits for a slot
+	tests := []struct {
+		algorithm  string
+		retryAfter time.Duration
+	}{
+		{constants.RATE_LIMIT_ALGORITHM_GCRA, 500 * time.Millisecond},
+		{constants.RATE_LIMIT_ALGORITHM_TOKEN_BUCKET, 500 * time.Millisecond},
+		{constants.RATE_LIMIT_ALGORITHM_SLIDING_WINDOW_LOG, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			ctx := context.Background()
+			fake := clock.NewFake(time.Unix(1700000000, 0))
+			limiter, err := NewLocalLimiter(Config{Algorithm: tt.algorithm, Limit: 2, Period: time.Second, Clock: fake})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, remaining := range []int{1, 0} {
+				result, err := limiter.Allow(ctx, "key")
+				if err != nil || !result.Allowed || result.Remaining != remaining {
+					t.Fatalf("request %d = %+v, %v, want allowed with %d remaining", i, result, err, remaining)
+				}
+			}
+			result, _ := limiter.Allow(ctx, "key")
+			if result.Allowed || result.RetryAfter != tt.retryAfter {
+				t.Fatalf("third request = %+v, want denied with RetryAfter %v", result, tt.retryAfter)
+			}
+
+			// the other keys are independent
+			if result, _ := limiter.Allow(ctx, "other"); !result.Allowed {
+				t.Errorf("other key = %+v, want allowed", result)
+			}
+
+			// a reservation within maxWait is allowed with a delay
+			result, _ = limiter.Reserve(ctx, "key", 1, tt.retryAfter)
+			if !result.Allowed || result.Delay != tt.retryAfter {
+				t.Errorf("reservation = %+v, want allowed with Delay %v", result, tt.retryAfter)
+			}
+
+			// the slots come back with the time
+			fake.Advance(2 * time.Second)
+			if result, _ := limiter.Allow(ctx, "key"); !result.Allowed {
+				t.Errorf("request after the period = %+v, want allowed", result)
+			}
+		})
+	}
+}
+
+func TestLocalLimiterBurst(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Unix(1700000000, 0))
+	limiter, err := NewLocalLimiter(Config{Limit: 1, Period: time.Second, Burst: 5, Clock: fake})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := limiter.AllowN(ctx, "key", 5); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("burst = %+v, want allowed with 0 remaining", result)
+	}
+	if _, err := limiter.AllowN(ctx, "key", 6); !errors.IsCode(err, constants.ERR_CODE_INVALID_ARGUMENT) {
+		t.Errorf("n above the burst error = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestWaitWithFakeClock(t *testing.T) {
+	// the fake clock is far from the real time, the ctx deadline must still be honoured with the real time
+	fake := clock.NewFake(time.Now().Add(2 * time.Hour))
+	limiter, err := NewLocalLimiter(Config{Limit: 1, Period: time.Second, Clock: fake})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if err := limiter.Wait(ctx, "key"); err != nil {
+		t.Fatalf("first Wait() = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- limiter.Wait(ctx, "key")
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Errorf("second Wait() = %v, want nil", err)
+	}
+
+	// a wait longer than the ctx deadline is refused at once
+	short, cancelShort := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancelShort()
+	if err := limiter.Wait(short, "key"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Wait() past the deadline = %v, want ErrRateLimited", err)
+	}
+}
@@ -0,0 +1,158 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/errors"
+	"github.com/gnanasuryateja/golib/validation"
+)
+
+const (
+	default_key_prefix    = "ratelimit:"
+	metadata_retry_key    = "retry_after_ms"
+	min_emission_interval = time.Microsecond
+)
+
+// ErrRateLimited is returned by Wait when the request cannot be allowed before the ctx deadline
+var ErrRateLimited = errors.ResourceExhausted("rate limit exceeded")
+
+type Config struct {
+	Algorithm string        `validate:"omitempty,oneof=token_bucket sliding_window_log gcra"` // Algorithm is one of constants.RATE_LIMIT_ALGORITHM_*, gcra by default |
+	Limit     int           `validate:"min=1"`                                                // Limit is the number of requests allowed per Period |
+	Period    time.Duration `validate:"min=1ms"`                                              // Period is the window of the Limit, e.g. 100 requests per time.Minute |
+	Burst     int           `validate:"min=0"`                                                // Burst is the number of requests allowed at once by the token bucket and gcra, Limit by default |
+	KeyPrefix string        // KeyPrefix is prepended to the redis keys, ratelimit: by default |
+	Clock     clock.Clock   `validate:"-"` // Clock drives the in-process limiter and the waits, the real clock by default
+}
+
+// validates the input params
+func (c Config) validate() error {
+	err := validation.Validate(c)
+	if err != nil {
+		return err
+	}
+
+	// the redis scripts count in microseconds, a shorter emission interval would be truncated to zero
+	if c.Period/time.Duration(c.Limit) < min_emission_interval {
+		return validation.ErrValidation.WithMessage("invalid Config... Period / Limit must be at least " + min_emission_interval.String())
+	}
+	return nil
+}
+
+// returns the config with the defaults filled in
+func (c Config) withDefaults() Config {
+	if c.Algorithm == "" {
+		c.Algorithm = constants.RATE_LIMIT_ALGORITHM_GCRA
+	}
+	if c.Burst == 0 || c.Algorithm == constants.RATE_LIMIT_ALGORITHM_SLIDING_WINDOW_LOG {
+		c.Burst = c.Limit
+	}
+	if c.KeyPrefix == "" {
+		c.KeyPrefix = default_key_prefix
+	}
+	c.Clock = clock.OrReal(c.Clock)
+	return c
+}
+
+// returns the time between two requests at the steady rate
+func (c Config) emissionInterval() time.Duration {
+	return c.Period / time.Duration(c.Limit)
+}
+
+// Result is the outcome of a single call to the limiter
+type Result struct {
+	Allowed    bool          // Allowed reports whether the requests were allowed (after Delay for reservations) |
+	Limit      int           // Limit is the number of requests allowed at once |
+	Remaining  int           // Remaining is the number of requests which would still be allowed now |
+	Delay      time.Duration // Delay is how long to wait before acting on an allowed reservation, zero for Allow |
+	RetryAfter time.Duration // RetryAfter is how long to wait before retrying a denied request |
+	ResetAfter time.Duration // ResetAfter is how long until the limiter is back to its full capacity
+}
+
+// backend is implemented by the in-process and the redis limiters |
+// take allows n requests for the key when they fit within maxWait, the allowed requests are consumed even when delayed
+type backend interface {
+	take(ctx context.Context, key string, n int, maxWait time.Duration) (Result, error)
+}
+
+// Limiter limits the requests per key with the configured algorithm
+type Limiter struct {
+	config  Config
+	backend backend
+}
+
+// returns the limiter config with the defaults filled in
+func (l *Limiter) Config() Config {
+	return l.config
+}
+
+// reports whether a request for the key is allowed now, the request is consumed when allowed
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+// reports whether n requests for the key are allowed now, the requests are consumed when allowed
+func (l *Limiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	return l.Reserve(ctx, key, n, 0)
+}
+
+// reserves n requests for the key when they are allowed within maxWait |
+// the caller must wait Result.Delay before acting, a reservation cannot be cancelled
+func (l *Limiter) Reserve(ctx context.Context, key string, n int, maxWait time.Duration) (Result, error) {
+
+	// validate the passed args
+	if key == "" {
+		return Result{}, errors.InvalidArgument("rate limit key cannot be empty")
+	}
+	if n < 1 || n > l.config.Burst {
+		return Result{}, errors.InvalidArgument("n must be between 1 and " + strconv.Itoa(l.config.Burst))
+	}
+	if maxWait < 0 {
+		maxWait = 0
+	}
+	return l.backend.take(ctx, key, n, maxWait)
+}
+
+// waits until a request for the key is allowed
+func (l *Limiter) Wait(ctx context.Context, key string) error {
+	return l.WaitN(ctx, key, 1)
+}
+
+// waits until n requests for the key are allowed, ErrRateLimited is returned when they are not allowed before the ctx deadline |
+// the requests stay consumed when ctx is done during the wait
+func (l *Limiter) WaitN(ctx context.Context, key string, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// the wait is bounded by the ctx deadline, which is set with the real time (the clock only drives the wait timer)
+	maxWait := time.Duration(math.MaxInt64)
+	if deadline, ok := ctx.Deadline(); ok {
+		maxWait = time.Until(deadline)
+	}
+	result, err := l.Reserve(ctx, key, n, maxWait)
+	if err != nil {
+		return err
+	}
+	if !result.Allowed {
+		return ErrRateLimited.WithMetadata(metadata_retry_key, result.RetryAfter.Milliseconds())
+	}
+	if result.Delay <= 0 {
+		return nil
+	}
+
+	// wait for the reservation
+	timer := l.config.Clock.NewTimer(result.Delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
@@ -0,0 +1,189 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/datastore/cache"
+	redisstore "github.com/gnanasuryateja/golib/datastore/cache/redis"
+	"github.com/gnanasuryateja/golib/errors"
+	"github.com/gnanasuryateja/golib/idgen"
+)
+
+const max_script_wait = 365 * 24 * time.Hour
+
+// the scripts use the redis server time (in microseconds) so that all the replicas share the same clock |
+// they return {allowed, remaining, delay or retry after, reset after}, the durations in microseconds
+
+// gcra keeps the theoretical arrival time in a string key
+var gcraScript = redis.NewScript(`
+redis.replicate_commands()
+local emission = tonumber(ARGV[1])
+local burst_offset = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local max_wait = tonumber(ARGV[4])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+local new_tat = tat + n * emission
+local delay = new_tat - burst_offset - now
+if delay > max_wait then
+	return {0, math.floor((burst_offset - (tat - now)) / emission), delay, tat - now}
+end
+if delay < 0 then
+	delay = 0
+end
+
+local reset_after = new_tat - now
+redis.call('SET', KEYS[1], string.format('%.0f', new_tat), 'PX', math.ceil(reset_after / 1000) + 1)
+return {1, math.floor((burst_offset - reset_after) / emission), delay, reset_after}
+`)
+
+// token bucket keeps the tokens and the time they were computed in a hash, it is refilled at ARGV[2] tokens per ARGV[5] microseconds
+var tokenBucketScript = redis.NewScript(`
+redis.replicate_commands()
+local capacity = tonumber(ARGV[1])
+local per_microsecond = tonumber(ARGV[2]) / tonumber(ARGV[5])
+local n = tonumber(ARGV[3])
+local max_wait = tonumber(ARGV[4])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) * per_microsecond)
+	ts = now
+end
+
+local delay = 0
+if tokens < n then
+	delay = math.ceil((n - tokens) / per_microsecond)
+end
+if delay > max_wait then
+	return {0, math.floor(math.max(tokens, 0)), delay, math.ceil((capacity - tokens) / per_microsecond)}
+end
+
+tokens = tokens - n
+local reset_after = math.ceil((capacity - tokens) / per_microsecond)
+redis.call('HSET', KEYS[1], 'tokens', string.format('%.6f', tokens), 'ts', string.format('%.0f', ts))
+redis.call('PEXPIRE', KEYS[1], math.ceil(reset_after / 1000) + 1)
+return {1, math.floor(math.max(tokens, 0)), delay, reset_after}
+`)
+
+// sliding window log keeps the requests in a sorted set scored by their time, ARGV[5] makes the members unique
+var slidingWindowLogScript = redis.NewScript(`
+redis.replicate_commands()
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local max_wait = tonumber(ARGV[4])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', string.format('%.0f', now - window))
+local count = redis.call('ZCARD', KEYS[1])
+local delay = 0
+if count + n > limit then
+	local oldest = redis.call('ZRANGE', KEYS[1], count + n - limit - 1, count + n - limit - 1, 'WITHSCORES')
+	delay = tonumber(oldest[2]) + window - now
+end
+if delay > max_wait then
+	local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+	return {0, math.max(limit - count, 0), delay, tonumber(newest[2]) + window - now}
+end
+
+local at = string.format('%.0f', now + delay)
+for i = 1, n do
+	redis.call('ZADD', KEYS[1], at, ARGV[5] .. ':' .. i)
+end
+local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+local reset_after = tonumber(newest[2]) + window - now
+redis.call('PEXPIRE', KEYS[1], math.ceil(reset_after / 1000) + 1)
+return {1, math.max(limit - count - n, 0), delay, reset_after}
+`)
+
+type redisLimiter struct {
+	config Config
+	client *redis.Client
+}
+
+// creates a new Limiter backed by the redis cache created by redis.NewRedisStoreClient |
+// the limits hold across all the instances sharing the redis, every call is a single atomic Lua script
+func NewRedisLimiter(c cache.Cache, config Config) (*Limiter, error) {
+
+	// validate the config
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+	config = config.withDefaults()
+
+	// get the go-redis client of the cache
+	client, err := redisstore.GetRedisClient(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Limiter{
+		config: config,
+		backend: &redisLimiter{
+			config: config,
+			client: client,
+		},
+	}, nil
+}
+
+func (rl *redisLimiter) take(ctx context.Context, key string, n int, maxWait time.Duration) (Result, error) {
+	keys := []string{rl.config.KeyPrefix + key}
+	maxWaitUs := formatMicroseconds(maxWait)
+
+	// run the script of the algorithm
+	var cmd *redis.Cmd
+	switch rl.config.Algorithm {
+	case constants.RATE_LIMIT_ALGORITHM_TOKEN_BUCKET:
+		cmd = tokenBucketScript.Run(ctx, rl.client, keys, rl.config.Burst, rl.config.Limit, n, maxWaitUs, rl.config.Period.Microseconds())
+	case constants.RATE_LIMIT_ALGORITHM_SLIDING_WINDOW_LOG:
+		cmd = slidingWindowLogScript.Run(ctx, rl.client, keys, rl.config.Limit, rl.config.Period.Microseconds(), n, maxWaitUs, idgen.NewULID().String())
+	default:
+		emission := rl.config.emissionInterval().Microseconds()
+		cmd = gcraScript.Run(ctx, rl.client, keys, emission, emission*int64(rl.config.Burst), n, maxWaitUs)
+	}
+	values, err := cmd.Int64Slice()
+	if err != nil {
+		return Result{}, redisstore.WrapError(err, "failed to run the rate limit script")
+	}
+	if len(values) != 4 {
+		return Result{}, errors.Internal("unexpected reply of the rate limit script")
+	}
+
+	result := Result{
+		Allowed:    values[0] == 1,
+		Limit:      rl.config.Burst,
+		Remaining:  int(max(values[1], 0)),
+		ResetAfter: time.Duration(values[3]) * time.Microsecond,
+	}
+	if result.Allowed {
+		result.Delay = time.Duration(values[2]) * time.Microsecond
+	} else {
+		result.RetryAfter = time.Duration(values[2]) * time.Microsecond
+	}
+	return result, nil
+}
+
+// formats the duration in microseconds, the unbounded waits are capped to a year to stay exact in Lua numbers
+func formatMicroseconds(d time.Duration) string {
+	if d > max_script_wait {
+		d = max_script_wait
+	}
+	return strconv.FormatInt(d.Microseconds(), 10)
+}
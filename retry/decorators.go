@@ -0,0 +1,47 @@
+package retry
+
+import (
+	"context"
+
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/datastore/cache"
+	"github.com/gnanasuryateja/golib/datastore/database"
+	"github.com/gnanasuryateja/golib/datastore/decorator"
+	messagingqueue "github.com/gnanasuryateja/golib/datastore/messaging_queue"
+)
+
+// wraps the cache so that every operation is retried with the policy, see Policy.RetryWrites for the writes
+func NewCache(c cache.Cache, policy Policy) cache.Cache {
+	return decorator.NewCache(c, interceptor(policy))
+}
+
+// wraps the database so that every operation is retried with the policy, see Policy.RetryWrites for the writes
+func NewDatabase(db database.Database, policy Policy) database.Database {
+	return decorator.NewDatabase(db, interceptor(policy))
+}
+
+// wraps the message queue so that every operation is retried with the policy, see Policy.RetryWrites for the writes
+func NewMessageQueue(messageQueue messagingqueue.MessageQueue, policy Policy) messagingqueue.MessageQueue {
+	return decorator.NewMessageQueue(messageQueue, interceptor(policy))
+}
+
+// returns the interceptor retrying the operations with the policy |
+// the writes are only retried on the errors of IsRetryableWrite unless RetryWrites is set
+func interceptor(policy Policy) decorator.Interceptor {
+	writePolicy := policy
+	if !policy.RetryWrites {
+		retryable := policy.Retryable
+		if retryable == nil {
+			retryable = IsRetryable
+		}
+		writePolicy.Retryable = func(err error) bool {
+			return retryable(err) && IsRetryableWrite(err)
+		}
+	}
+	return func(ctx context.Context, operation decorator.Operation, fn func(ctx context.Context) error) error {
+		if operation.Kind == constants.OPERATION_KIND_WRITE {
+			return Do(ctx, writePolicy, fn)
+		}
+		return Do(ctx, policy, fn)
+	}
+}
@@ -0,0 +1,51 @@
+package retry
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gnanasuryateja/golib/circuitbreaker"
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/datastore"
+	"github.com/gnanasuryateja/golib/datastore/decorator"
+)
+
+func TestInterceptorWrites(t *testing.T) {
+	dialErr := datastore.Wrap(datastore.ErrConnection, "error connecting", &net.OpError{Op: "dial", Err: net.UnknownNetworkError("tcp")})
+	readErr := datastore.Wrap(datastore.ErrConnection, "error reading", &net.OpError{Op: "read", Err: net.UnknownNetworkError("tcp")})
+
+	tests := []struct {
+		name        string
+		kind        string
+		err         error
+		retryWrites bool
+		want        int
+	}{
+		{"read timeout is retried", constants.OPERATION_KIND_READ, datastore.ErrTimeout, false, 3},
+		{"write timeout is not retried", constants.OPERATION_KIND_WRITE, datastore.ErrTimeout, false, 1},
+		{"write read failure is not retried", constants.OPERATION_KIND_WRITE, readErr, false, 1},
+		{"write dial failure is retried", constants.OPERATION_KIND_WRITE, dialErr, false, 3},
+		{"write on open breaker is retried", constants.OPERATION_KIND_WRITE, circuitbreaker.ErrOpen, false, 3},
+		{"write timeout is retried with RetryWrites", constants.OPERATION_KIND_WRITE, datastore.ErrTimeout, true, 3},
+		{"write invalid args is never retried", constants.OPERATION_KIND_WRITE, datastore.ErrInvalidArgs, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intercept := interceptor(Policy{
+				MaxAttempts:    3,
+				InitialBackoff: time.Microsecond,
+				RetryWrites:    tt.retryWrites,
+			})
+			attempts := 0
+			_ = intercept(context.Background(), decorator.Operation{Name: "op", Kind: tt.kind}, func(ctx context.Context) error {
+				attempts++
+				return tt.err
+			})
+			if attempts != tt.want {
+				t.Errorf("attempts = %d, want %d", attempts, tt.want)
+			}
+		})
+	}
+}
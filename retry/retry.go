@@ -0,0 +1,157 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/gnanasuryateja/golib/circuitbreaker"
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/datastore"
+	"github.com/gnanasuryateja/golib/errors"
+	"github.com/gnanasuryateja/golib/validation"
+)
+
+const (
+	default_max_attempts    = 3
+	default_initial_backoff = 100 * time.Millisecond
+	default_max_backoff     = 10 * time.Second
+	default_multiplier      = 2
+	net_dial_op             = "dial"
+)
+
+type Policy struct {
+	MaxAttempts    int                  `validate:"min=0"`                                  // MaxAttempts is the number of attempts including the first one, 3 by default |
+	InitialBackoff time.Duration        `validate:"min=0"`                                  // InitialBackoff is the backoff before the first retry, 100ms by default |
+	MaxBackoff     time.Duration        `validate:"min=0"`                                  // MaxBackoff caps the backoff, 10s by default |
+	Multiplier     float64              `validate:"min=0"`                                  // Multiplier grows the backoff exponentially, 2 by default |
+	Jitter         string               `validate:"omitempty,oneof=none full decorrelated"` // Jitter is one of constants.RETRY_JITTER_*, full jitter by default |
+	Retryable      func(err error) bool // Retryable classifies the errors which are retried, IsRetryable by default |
+	RetryWrites    bool                 // RetryWrites lets the decorators retry the writes like the reads, set it only when the writes are idempotent |
+	Clock          clock.Clock          // Clock is used to wait for the backoff, the real clock by default
+}
+
+// validates the input params
+func (p Policy) validate() error {
+	return validation.Validate(p)
+}
+
+// returns the policy with the defaults filled in
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = default_max_attempts
+	}
+	if p.InitialBackoff == 0 {
+		p.InitialBackoff = default_initial_backoff
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = default_max_backoff
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = default_multiplier
+	}
+	if p.Jitter == "" {
+		p.Jitter = constants.RETRY_JITTER_FULL
+	}
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+	p.Clock = clock.OrReal(p.Clock)
+	return p
+}
+
+// returns the backoff before the retry following the attempt (starting at 1)
+func (p Policy) backoff(attempt int, previous time.Duration) time.Duration {
+	exponential := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	capped := time.Duration(math.Min(exponential, float64(p.MaxBackoff)))
+	switch p.Jitter {
+	case constants.RETRY_JITTER_FULL:
+		return time.Duration(rand.Int64N(int64(capped) + 1))
+	case constants.RETRY_JITTER_DECORRELATED:
+		// sleep = min(max, random between initial and 3 * previous sleep)
+		if previous < p.InitialBackoff {
+			previous = p.InitialBackoff
+		}
+		upper := int64(previous) * 3
+		decorrelated := time.Duration(int64(p.InitialBackoff) + rand.Int64N(upper-int64(p.InitialBackoff)+1))
+		if decorrelated > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+		return decorrelated
+	}
+	return capped
+}
+
+// reports whether the error is transient, connection failures and timeouts are retried
+func IsRetryable(err error) bool {
+	return errors.Is(err, datastore.ErrConnection) ||
+		errors.Is(err, datastore.ErrTimeout) ||
+		errors.IsCode(err, constants.ERR_CODE_UNAVAILABLE, constants.ERR_CODE_DEADLINE_EXCEEDED, constants.ERR_CODE_RESOURCE_EXHAUSTED)
+}
+
+// reports whether the error is known to have happened before the operation was sent (dial failures, open circuit breaker) |
+// the decorators retry the writes only on these errors by default since a timed out write may have landed
+func IsRetryableWrite(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == net_dial_op {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, circuitbreaker.ErrOpen)
+}
+
+// calls fn until it succeeds, returns a non retryable error or the attempts run out |
+// it stops early when ctx is done or when the next backoff would pass the ctx deadline
+func Do(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	_, err := DoValue(ctx, policy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// same as Do for functions returning a value
+func DoValue[T any](ctx context.Context, policy Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+
+	// validate the policy
+	err := policy.validate()
+	if err != nil {
+		return zero, err
+	}
+	policy = policy.withDefaults()
+
+	var backoff time.Duration
+	for attempt := 1; ; attempt++ {
+		value, err := fn(ctx)
+		if err == nil {
+			return value, nil
+		}
+
+		// check if the error should be retried
+		if !policy.Retryable(err) || ctx.Err() != nil {
+			return zero, err
+		}
+		if attempt >= policy.MaxAttempts {
+			return zero, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		// do not sleep past the ctx deadline, which is set with the real time (the clock only drives the backoff timer)
+		backoff = policy.backoff(attempt, backoff)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			return zero, fmt.Errorf("giving up after %d attempts, ctx deadline is too close: %w", attempt, err)
+		}
+
+		// wait for the backoff
+		timer := policy.Clock.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, err
+		case <-timer.C():
+		}
+	}
+}
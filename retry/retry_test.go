@@ -0,0 +1,86 @@
+package retry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gnanasuryateja/golib/clock"
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/datastore"
+)
+
+func TestDoWithFakeClock(t *testing.T) {
+	// the fake clock is far from the real time, the ctx deadline must still be honoured with the real time
+	fake := clock.NewFake(time.Now().Add(2 * time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	attempts := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- Do(ctx, Policy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Second,
+			Jitter:         constants.RETRY_JITTER_NONE,
+			Clock:          fake,
+		}, func(ctx context.Context) error {
+			attempts++
+			return datastore.ErrTimeout
+		})
+	}()
+
+	// the backoffs wait on the fake clock
+	for i := 0; i < 2; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(time.Minute)
+	}
+	err := <-done
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+	if err == nil {
+		t.Error("Do returned nil, want the last error")
+	}
+}
+
+func TestDoStopsBeforeDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	attempts := 0
+	_ = Do(ctx, Policy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Minute,
+		Jitter:         constants.RETRY_JITTER_NONE,
+		Clock:          clock.NewFake(time.Unix(0, 0)),
+	}, func(ctx context.Context) error {
+		attempts++
+		return datastore.ErrTimeout
+	})
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := Policy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Jitter:         constants.RETRY_JITTER_NONE,
+	}.withDefaults()
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{5, time.Second},
+	}
+	for _, tt := range tests {
+		if got := policy.backoff(tt.attempt, 0); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
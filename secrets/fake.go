@@ -0,0 +1,48 @@
+package secrets
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeProvider is an in memory Provider for local development and tests |
+// Set can be used to simulate the rotation of a secret
+type FakeProvider struct {
+	lock    sync.RWMutex
+	secrets map[string][]byte
+}
+
+// creates a new FakeProvider with the passed secrets
+func NewFakeProvider(secrets map[string]string) *FakeProvider {
+	fakeProvider := &FakeProvider{
+		secrets: make(map[string][]byte, len(secrets)),
+	}
+	for path, secret := range secrets {
+		fakeProvider.secrets[path] = []byte(secret)
+	}
+	return fakeProvider
+}
+
+// sets (or rotates) the secret at the path
+func (fp *FakeProvider) Set(path string, secret string) {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+	fp.secrets[path] = []byte(secret)
+}
+
+// deletes the secret at the path
+func (fp *FakeProvider) Delete(path string) {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+	delete(fp.secrets, path)
+}
+
+func (fp *FakeProvider) GetSecret(ctx context.Context, path string) ([]byte, error) {
+	fp.lock.RLock()
+	defer fp.lock.RUnlock()
+	secret, ok := fp.secrets[path]
+	if !ok {
+		return nil, ErrSecretNotFound.WithMessagef("secret %s does not exist", path)
+	}
+	return append([]byte(nil), secret...), nil
+}
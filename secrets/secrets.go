@@ -0,0 +1,140 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+const (
+	SCHEME_ENV    = "env"
+	SCHEME_FILE   = "file"
+	SCHEME_BASE64 = "base64"
+
+	scheme_divider        = "://"
+	base64_scheme_divider = ":"
+)
+
+var (
+	ErrSecretNotFound = errors.NotFound("secret not found")
+	ErrInvalidSecret  = errors.InvalidArgument("invalid secret reference")
+)
+
+// Provider fetches the secret at the path from a backend (env, file, vault, ...)
+type Provider interface {
+	GetSecret(ctx context.Context, path string) ([]byte, error)
+}
+
+// ProviderFunc adapts a function to the Provider interface
+type ProviderFunc func(ctx context.Context, path string) ([]byte, error)
+
+func (pf ProviderFunc) GetSecret(ctx context.Context, path string) ([]byte, error) {
+	return pf(ctx, path)
+}
+
+// Resolver resolves secret references like env://VAR, file:///run/secrets/x and base64://... (or base64:...) |
+// values which are not scheme:// references of a registered scheme are returned as they are, e.g. the password env:abc
+type Resolver struct {
+	lock      sync.RWMutex
+	providers map[string]Provider
+}
+
+// creates a new Resolver with the env, file and base64 providers registered
+func NewResolver() *Resolver {
+	resolver := &Resolver{
+		providers: map[string]Provider{},
+	}
+	resolver.Register(SCHEME_ENV, ProviderFunc(getEnvSecret))
+	resolver.Register(SCHEME_FILE, ProviderFunc(getFileSecret))
+	resolver.Register(SCHEME_BASE64, ProviderFunc(getBase64Secret))
+	return resolver
+}
+
+// registers the provider for the scheme, replacing any existing provider
+func (r *Resolver) Register(scheme string, provider Provider) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.providers[strings.ToLower(scheme)] = provider
+}
+
+// reports whether the value is a reference of a registered scheme
+func (r *Resolver) IsReference(value string) bool {
+	_, _, ok := r.parse(value)
+	return ok
+}
+
+// resolves the value if it is a reference, otherwise returns the value as it is
+func (r *Resolver) Resolve(ctx context.Context, value string) ([]byte, error) {
+	provider, path, ok := r.parse(value)
+	if !ok {
+		return []byte(value), nil
+	}
+	secret, err := provider.GetSecret(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// resolves the value into a string, the trailing new line (as written by most secret mounts) is trimmed
+func (r *Resolver) ResolveString(ctx context.Context, value string) (string, error) {
+	secret, err := r.Resolve(ctx, value)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(secret), "\r\n"), nil
+}
+
+// splits the value into the provider of its scheme and the path, the path is kept as it is |
+// base64 also accepts the base64:... form (the alphabet has no colon), payloads starting with // need base64://
+func (r *Resolver) parse(value string) (Provider, string, bool) {
+	scheme, path, found := strings.Cut(value, scheme_divider)
+	if !found {
+		scheme, path, found = strings.Cut(value, base64_scheme_divider)
+		if !found || !strings.EqualFold(scheme, SCHEME_BASE64) {
+			return nil, "", false
+		}
+	}
+	r.lock.RLock()
+	provider, ok := r.providers[strings.ToLower(scheme)]
+	r.lock.RUnlock()
+	if !ok {
+		return nil, "", false
+	}
+	return provider, path, true
+}
+
+// returns the value of the env var
+func getEnvSecret(ctx context.Context, path string) ([]byte, error) {
+	value, ok := os.LookupEnv(path)
+	if !ok {
+		return nil, ErrSecretNotFound.WithMessagef("env var %s is not set", path)
+	}
+	return []byte(value), nil
+}
+
+// returns the content of the file
+func getFileSecret(ctx context.Context, path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrSecretNotFound.WithMessagef("file %s does not exist", path).Wrap(err)
+		}
+		return nil, fmt.Errorf("failed to read the secret file %s: %w", path, err)
+	}
+	return content, nil
+}
+
+// returns the decoded base64 value
+func getBase64Secret(ctx context.Context, path string) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(path)
+	if err != nil {
+		return nil, ErrInvalidSecret.WithMessage("invalid base64 secret").Wrap(err)
+	}
+	return decoded, nil
+}
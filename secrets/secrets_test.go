@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+func TestResolveString(t *testing.T) {
+	t.Setenv("SECRETS_TEST_PASSWORD", "from-env\n")
+	resolver := NewResolver()
+	resolver.Register("fake", NewFakeProvider(map[string]string{"db/password": "from-fake"}))
+	slashes := base64.StdEncoding.EncodeToString([]byte{0xff, 0xff, 0xff})
+
+	tests := []struct {
+		name      string
+		value     string
+		want      string
+		reference bool
+	}{
+		{"plain value", "s3cret", "s3cret", false},
+		{"env reference", "env://SECRETS_TEST_PASSWORD", "from-env", true},
+		{"scheme without slashes is a plain value", "env:abc", "env:abc", false},
+		{"unknown scheme is a plain value", "vault://db/password", "vault://db/password", false},
+		{"registered provider", "fake://db/password", "from-fake", true},
+		{"base64 reference", "base64://" + base64.StdEncoding.EncodeToString([]byte("decoded")), "decoded", true},
+		{"base64 payload starting with slashes", "base64://" + slashes, string([]byte{0xff, 0xff, 0xff}), true},
+		{"single colon base64 reference", "base64:" + base64.StdEncoding.EncodeToString([]byte("pass")), "pass", true},
+		{"single colon base64 is case insensitive", "BASE64:" + base64.StdEncoding.EncodeToString([]byte("pass")), "pass", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolver.IsReference(tt.value); got != tt.reference {
+				t.Errorf("IsReference(%q) = %v, want %v", tt.value, got, tt.reference)
+			}
+			got, err := resolver.ResolveString(context.Background(), tt.value)
+			if err != nil {
+				t.Fatalf("ResolveString(%q) error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveString(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	resolver := NewResolver()
+	tests := []struct {
+		name  string
+		value string
+		want  error
+	}{
+		{"missing env var", "env://SECRETS_TEST_MISSING", ErrSecretNotFound},
+		{"missing file", "file:///nonexistent/secret", ErrSecretNotFound},
+		{"invalid base64", "base64://not base64", ErrInvalidSecret},
+		{"invalid single colon base64", "base64:not base64", ErrInvalidSecret},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolver.Resolve(context.Background(), tt.value)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Resolve(%q) error = %v, want %v", tt.value, err, tt.want)
+			}
+		})
+	}
+}
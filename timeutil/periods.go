@@ -0,0 +1,96 @@
+package timeutil
+
+import (
+	"time"
+)
+
+// returns the time in loc, the location of t is kept when loc is nil
+func in(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return t
+	}
+	return t.In(loc)
+}
+
+// returns the start of the day of t in loc
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	t = in(t, loc)
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// returns the last nanosecond of the day of t in loc
+func EndOfDay(t time.Time, loc *time.Location) time.Time {
+	return StartOfDay(t, loc).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// returns the start of the week of t in loc, weeks start on weekStart
+func StartOfWeek(t time.Time, loc *time.Location, weekStart time.Weekday) time.Time {
+	start := StartOfDay(t, loc)
+	offset := (int(start.Weekday()) - int(weekStart) + 7) % 7
+	return start.AddDate(0, 0, -offset)
+}
+
+// returns the last nanosecond of the week of t in loc, weeks start on weekStart
+func EndOfWeek(t time.Time, loc *time.Location, weekStart time.Weekday) time.Time {
+	return StartOfWeek(t, loc, weekStart).AddDate(0, 0, 7).Add(-time.Nanosecond)
+}
+
+// returns the start of the month of t in loc
+func StartOfMonth(t time.Time, loc *time.Location) time.Time {
+	t = in(t, loc)
+	year, month, _ := t.Date()
+	return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+}
+
+// returns the last nanosecond of the month of t in loc
+func EndOfMonth(t time.Time, loc *time.Location) time.Time {
+	return StartOfMonth(t, loc).AddDate(0, 1, 0).Add(-time.Nanosecond)
+}
+
+// reports whether t falls on a weekday which is not one of the holidays (compared by date in the location of t)
+func IsBusinessDay(t time.Time, holidays ...time.Time) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	year, month, day := t.Date()
+	for _, holiday := range holidays {
+		holidayYear, holidayMonth, holidayDay := holiday.In(t.Location()).Date()
+		if year == holidayYear && month == holidayMonth && day == holidayDay {
+			return false
+		}
+	}
+	return true
+}
+
+// adds n business days to t, n can be negative
+func AddBusinessDays(t time.Time, n int, holidays ...time.Time) time.Time {
+	step := 1
+	if n < 0 {
+		step = -1
+		n = -n
+	}
+	for n > 0 {
+		t = t.AddDate(0, 0, step)
+		if IsBusinessDay(t, holidays...) {
+			n--
+		}
+	}
+	return t
+}
+
+// returns the number of business days after start up to and including end, negative when end is before start
+func BusinessDaysBetween(start time.Time, end time.Time, holidays ...time.Time) int {
+	sign := 1
+	if end.Before(start) {
+		start, end = end, start
+		sign = -1
+	}
+	count := 0
+	for day := StartOfDay(start, nil).AddDate(0, 0, 1); !day.After(end); day = day.AddDate(0, 0, 1) {
+		if IsBusinessDay(day, holidays...) {
+			count++
+		}
+	}
+	return sign * count
+}
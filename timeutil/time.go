@@ -0,0 +1,120 @@
+package timeutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+// Layout chooses the format in which Time is marshalled
+type Layout interface {
+	Layout() string
+}
+
+// the layouts which can be used with Time
+
+type HumanReadableLayout struct{}
+
+func (HumanReadableLayout) Layout() string { return constants.HUMAN_READABLE_TIME_FORMAT }
+
+type SimpleLoggerLayout struct{}
+
+func (SimpleLoggerLayout) Layout() string { return constants.SIMPLE_LOGGER_TIME_FORMAT }
+
+type RFC3339Layout struct{}
+
+func (RFC3339Layout) Layout() string { return time.RFC3339 }
+
+type DateOnlyLayout struct{}
+
+func (DateOnlyLayout) Layout() string { return time.DateOnly }
+
+// Time is a time.Time which is marshalled to json and bson as a UTC string in the format of L |
+// e.g. timeutil.Time[timeutil.HumanReadableLayout], the zero time is marshalled as null
+type Time[L Layout] struct {
+	time.Time
+}
+
+// wraps the time.Time
+func NewTime[L Layout](t time.Time) Time[L] {
+	return Time[L]{Time: t}
+}
+
+// returns the time formatted in UTC with the layout of L |
+// the layouts without an offset (e.g. the literal Z of SimpleLoggerLayout) would shift the non UTC times when parsed back
+func (t Time[L]) String() string {
+	var layout L
+	return t.UTC().Format(layout.Layout())
+}
+
+func (t Time[L]) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.String())
+}
+
+func (t *Time[L]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		t.Time = time.Time{}
+		return nil
+	}
+	var value string
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return errors.Wrap(err, constants.ERR_CODE_INVALID_ARGUMENT, "time must be a json string")
+	}
+	return t.parse(value)
+}
+
+func (t Time[L]) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if t.IsZero() {
+		return bson.TypeNull, nil, nil
+	}
+	return bson.TypeString, bsoncore.AppendString(nil, t.String()), nil
+}
+
+// strings in any layout accepted by Parse and bson dates are accepted
+func (t *Time[L]) UnmarshalBSONValue(bsonType bsontype.Type, data []byte) error {
+	value := bsoncore.Value{Type: bsonType, Data: data}
+	switch bsonType {
+	case bson.TypeNull:
+		t.Time = time.Time{}
+		return nil
+	case bson.TypeDateTime:
+		millis, ok := value.DateTimeOK()
+		if !ok {
+			return errors.InvalidArgument("invalid bson date time")
+		}
+		t.Time = time.UnixMilli(millis).UTC()
+		return nil
+	case bson.TypeString:
+		str, ok := value.StringValueOK()
+		if !ok {
+			return errors.InvalidArgument("invalid bson string")
+		}
+		return t.parse(str)
+	}
+	return errors.InvalidArgument("cannot unmarshal bson " + bsonType.String() + " into time")
+}
+
+// parses the value with the layout of L and falls back to the lenient Parse
+func (t *Time[L]) parse(value string) error {
+	var layout L
+	parsed, err := Parse(value, time.UTC, layout.Layout())
+	if err != nil {
+		parsed, err = Parse(value, time.UTC)
+		if err != nil {
+			return err
+		}
+	}
+	t.Time = parsed
+	return nil
+}
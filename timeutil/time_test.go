@@ -0,0 +1,75 @@
+package timeutil
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type timeDocument[L Layout] struct {
+	At Time[L] `json:"at" bson:"at"`
+}
+
+// a non UTC time, the round trips must not shift it
+var ist = time.Date(2024, time.March, 1, 10, 0, 0, 0, time.FixedZone("IST", 5*60*60+30*60))
+
+func testRoundTrip[L Layout](t *testing.T, in time.Time, want time.Time) {
+	t.Helper()
+	doc := timeDocument[L]{At: NewTime[L](in)}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	var fromJSON timeDocument[L]
+	if err := json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error: %v", data, err)
+	}
+	if !fromJSON.At.Equal(want) {
+		t.Errorf("json round trip of %v through %s = %v, want %v", in, data, fromJSON.At.Time, want)
+	}
+
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("bson.Marshal() error: %v", err)
+	}
+	var fromBSON timeDocument[L]
+	if err := bson.Unmarshal(raw, &fromBSON); err != nil {
+		t.Fatalf("bson.Unmarshal() error: %v", err)
+	}
+	if !fromBSON.At.Equal(want) {
+		t.Errorf("bson round trip of %v = %v, want %v", in, fromBSON.At.Time, want)
+	}
+}
+
+func TestTimeRoundTrip(t *testing.T) {
+	t.Run("simple logger layout", func(t *testing.T) { testRoundTrip[SimpleLoggerLayout](t, ist, ist) })
+	t.Run("human readable layout", func(t *testing.T) { testRoundTrip[HumanReadableLayout](t, ist, ist) })
+	t.Run("rfc3339 layout", func(t *testing.T) { testRoundTrip[RFC3339Layout](t, ist, ist) })
+	t.Run("date only layout", func(t *testing.T) {
+		testRoundTrip[DateOnlyLayout](t, ist, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+	})
+	t.Run("utc", func(t *testing.T) { testRoundTrip[SimpleLoggerLayout](t, ist.UTC(), ist) })
+}
+
+func TestTimeString(t *testing.T) {
+	if got, want := NewTime[SimpleLoggerLayout](ist).String(), "2024-03-01T04:30:00Z"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestZeroTime(t *testing.T) {
+	data, err := json.Marshal(timeDocument[RFC3339Layout]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"at":null}` {
+		t.Errorf("json.Marshal() of the zero time = %s, want null", data)
+	}
+	var doc timeDocument[RFC3339Layout]
+	if err := json.Unmarshal(data, &doc); err != nil || !doc.At.IsZero() {
+		t.Errorf("json.Unmarshal(%s) = %v, %v, want the zero time", data, doc.At.Time, err)
+	}
+}
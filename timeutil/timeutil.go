@@ -0,0 +1,144 @@
+package timeutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gnanasuryateja/golib/constants"
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+// DefaultLayouts are tried in order by Parse when no layouts are passed
+var DefaultLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	constants.SIMPLE_LOGGER_TIME_FORMAT,
+	constants.HUMAN_READABLE_TIME_FORMAT,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.DateOnly,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.UnixDate,
+	time.ANSIC,
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006",
+	"Jan 2, 2006 15:04:05",
+	"Jan 2, 2006",
+	"02/01/2006",
+}
+
+// cache of the loaded locations
+var locations sync.Map
+
+// loads the location of the named zone (e.g. Asia/Kolkata), the locations are cached
+func LoadLocation(zone string) (*time.Location, error) {
+	if location, ok := locations.Load(zone); ok {
+		return location.(*time.Location), nil
+	}
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, errors.Wrap(err, constants.ERR_CODE_INVALID_ARGUMENT, fmt.Sprintf("invalid time zone %s", zone))
+	}
+	locations.Store(zone, location)
+	return location, nil
+}
+
+// parses the value trying the layouts (DefaultLayouts when none are passed) in order |
+// surrounding spaces are ignored and unix timestamps in seconds or milliseconds are accepted |
+// values without a zone are parsed in loc (UTC when nil)
+func Parse(value string, loc *time.Location, layouts ...string) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return time.Time{}, errors.InvalidArgument("time value cannot be empty")
+	}
+
+	// unix timestamps, 13 or more digits are treated as milliseconds
+	if unix, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		if len(strings.TrimPrefix(trimmed, "-")) >= 13 {
+			return time.UnixMilli(unix).In(loc), nil
+		}
+		return time.Unix(unix, 0).In(loc), nil
+	}
+
+	if len(layouts) == 0 {
+		layouts = DefaultLayouts
+	}
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, trimmed, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.InvalidArgument(fmt.Sprintf("cannot parse %q as time, none of the %d layouts match", value, len(layouts)))
+}
+
+// formats the time with the layout in the named zone
+func FormatIn(t time.Time, layout string, zone string) (string, error) {
+	location, err := LoadLocation(zone)
+	if err != nil {
+		return "", err
+	}
+	return t.In(location).Format(layout), nil
+}
+
+// formats the time with constants.HUMAN_READABLE_TIME_FORMAT in the named zone
+func HumanReadable(t time.Time, zone string) (string, error) {
+	return FormatIn(t, constants.HUMAN_READABLE_TIME_FORMAT, zone)
+}
+
+// renders the time relative to now, e.g. "3 hours ago", "in 2 days" or "just now"
+func Relative(t time.Time, now time.Time) string {
+	diff := now.Sub(t)
+	future := diff < 0
+	if future {
+		diff = -diff
+	}
+	if diff < time.Second {
+		return "just now"
+	}
+
+	units := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+		{"second", time.Second},
+	}
+	for _, unit := range units {
+		count := int64(diff / unit.duration)
+		if count == 0 {
+			continue
+		}
+		name := unit.name
+		if count > 1 {
+			name += "s"
+		}
+		if future {
+			return fmt.Sprintf("in %d %s", count, name)
+		}
+		return fmt.Sprintf("%d %s ago", count, name)
+	}
+	return "just now"
+}
+
+// renders the time relative to the current time
+func Since(t time.Time) string {
+	return Relative(t, time.Now())
+}
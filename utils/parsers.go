@@ -1,9 +1,96 @@
 package utils
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 func StringToStringPtr(str string) *string {
-	return &str
+	return Ptr(str)
 }
 
 func IntToIntPtr(num int) *int {
-	return &num
+	return Ptr(num)
+}
+
+// ParseError is returned when a string cannot be parsed into the requested kind
+type ParseError struct {
+	Value string // Value is the string which was passed |
+	Kind  string // Kind is the kind it was parsed into (int, int64, uint64, float64, bool, duration) |
+	Err   error  // Err is the underlying parse error
+}
+
+func (pe *ParseError) Error() string {
+	return fmt.Sprintf("cannot parse %q as %s: %v", pe.Value, pe.Kind, pe.Err)
+}
+
+func (pe *ParseError) Unwrap() error {
+	return pe.Err
+}
+
+// parses the string into an int
+func ParseInt(str string) (int, error) {
+	num, err := strconv.Atoi(strings.TrimSpace(str))
+	if err != nil {
+		return 0, &ParseError{Value: str, Kind: "int", Err: err}
+	}
+	return num, nil
+}
+
+// parses the string into an int64
+func ParseInt64(str string) (int64, error) {
+	num, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64)
+	if err != nil {
+		return 0, &ParseError{Value: str, Kind: "int64", Err: err}
+	}
+	return num, nil
+}
+
+// parses the string into an uint64
+func ParseUint64(str string) (uint64, error) {
+	num, err := strconv.ParseUint(strings.TrimSpace(str), 10, 64)
+	if err != nil {
+		return 0, &ParseError{Value: str, Kind: "uint64", Err: err}
+	}
+	return num, nil
+}
+
+// parses the string into a float64
+func ParseFloat64(str string) (float64, error) {
+	num, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+	if err != nil {
+		return 0, &ParseError{Value: str, Kind: "float64", Err: err}
+	}
+	return num, nil
+}
+
+// parses the string into a bool, yes/no and on/off are accepted along with the strconv values
+func ParseBool(str string) (bool, error) {
+	trimmed := strings.TrimSpace(str)
+	switch strings.ToLower(trimmed) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	b, err := strconv.ParseBool(trimmed)
+	if err != nil {
+		return false, &ParseError{Value: str, Kind: "bool", Err: err}
+	}
+	return b, nil
+}
+
+// parses the string into a time.Duration, plain integers are treated as seconds
+func ParseDuration(str string) (time.Duration, error) {
+	trimmed := strings.TrimSpace(str)
+	if seconds, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	duration, err := time.ParseDuration(trimmed)
+	if err != nil {
+		return 0, &ParseError{Value: str, Kind: "duration", Err: err}
+	}
+	return duration, nil
 }
@@ -0,0 +1,41 @@
+package utils
+
+// returns a pointer to the passed value
+func Ptr[T any](value T) *T {
+	return &value
+}
+
+// returns the value pointed to by ptr, or def when ptr is nil
+func Deref[T any](ptr *T, def T) T {
+	if ptr == nil {
+		return def
+	}
+	return *ptr
+}
+
+// returns value when it is not the zero value, otherwise def
+func ValueOr[T comparable](value T, def T) T {
+	var zero T
+	if value == zero {
+		return def
+	}
+	return value
+}
+
+// returns the first non nil pointer, or nil when all of them are nil
+func Coalesce[T any](ptrs ...*T) *T {
+	for _, ptr := range ptrs {
+		if ptr != nil {
+			return ptr
+		}
+	}
+	return nil
+}
+
+// reports whether both pointers are nil or point to equal values
+func Equal[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,151 @@
+package validation
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	oneof_divider = " "
+	max_port      = 65535
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// the rules available to all the validate tags, required, omitempty, omitnil and dive are handled by validateValue
+var builtinRules = map[string]Rule{
+	"oneof":       oneOf(false),
+	"oneofci":     oneOf(true),
+	"min":         bound(true),
+	"max":         bound(false),
+	"url":         validURL,
+	"file-exists": fileExists,
+	"file_exists": fileExists, // file_exists is an alias of file-exists
+	"hostport":    hostPort,
+}
+
+// oneof=a b c checks that the value is one of the space separated options, oneofci ignores the case
+func oneOf(caseInsensitive bool) Rule {
+	return func(value reflect.Value, param string) error {
+		actual := fmt.Sprint(value.Interface())
+		options := strings.Fields(param)
+		for _, option := range options {
+			if actual == option || (caseInsensitive && strings.EqualFold(actual, option)) {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of [%s], got %s", strings.Join(options, oneof_divider), actual)
+	}
+}
+
+// min=n and max=n bound numbers by value and strings, slices and maps by length |
+// durations accept the time.ParseDuration format (e.g. max=30s)
+func bound(isMin bool) Rule {
+	name, word := "max", "at most"
+	if isMin {
+		name, word = "min", "at least"
+	}
+	return func(value reflect.Value, param string) error {
+		switch value.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			limit, err := strconv.Atoi(param)
+			if err != nil {
+				return fmt.Errorf("invalid %s param %s", name, param)
+			}
+			length := value.Len()
+			if value.Kind() == reflect.String {
+				length = utf8.RuneCountInString(value.String())
+			}
+			if (isMin && length < limit) || (!isMin && length > limit) {
+				return fmt.Errorf("length must be %s %d", word, limit)
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if value.Type() == durationType {
+				limit, err := time.ParseDuration(param)
+				if err == nil {
+					actual := time.Duration(value.Int())
+					if (isMin && actual < limit) || (!isMin && actual > limit) {
+						return fmt.Errorf("must be %s %s", word, limit)
+					}
+					return nil
+				}
+			}
+			limit, err := strconv.ParseInt(param, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s param %s", name, param)
+			}
+			if (isMin && value.Int() < limit) || (!isMin && value.Int() > limit) {
+				return fmt.Errorf("must be %s %d", word, limit)
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			limit, err := strconv.ParseUint(param, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s param %s", name, param)
+			}
+			if (isMin && value.Uint() < limit) || (!isMin && value.Uint() > limit) {
+				return fmt.Errorf("must be %s %d", word, limit)
+			}
+		case reflect.Float32, reflect.Float64:
+			limit, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s param %s", name, param)
+			}
+			if (isMin && value.Float() < limit) || (!isMin && value.Float() > limit) {
+				return fmt.Errorf("must be %s %s", word, param)
+			}
+		default:
+			return fmt.Errorf("%s is not supported for %s", name, value.Kind())
+		}
+		return nil
+	}
+}
+
+// url checks that the string is an absolute url with a scheme and a host
+func validURL(value reflect.Value, _ string) error {
+	if value.Kind() != reflect.String {
+		return fmt.Errorf("url is not supported for %s", value.Kind())
+	}
+	parsed, err := url.Parse(value.String())
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("must be a valid url")
+	}
+	return nil
+}
+
+// file-exists checks that the string is the path of an existing file
+func fileExists(value reflect.Value, _ string) error {
+	if value.Kind() != reflect.String {
+		return fmt.Errorf("file-exists is not supported for %s", value.Kind())
+	}
+	info, err := os.Stat(value.String())
+	if err != nil {
+		return fmt.Errorf("file %s does not exist", value.String())
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", value.String())
+	}
+	return nil
+}
+
+// hostport checks that the string is host:port with a numeric port
+func hostPort(value reflect.Value, _ string) error {
+	if value.Kind() != reflect.String {
+		return fmt.Errorf("hostport is not supported for %s", value.Kind())
+	}
+	_, port, err := net.SplitHostPort(value.String())
+	if err != nil {
+		return fmt.Errorf("must be host:port")
+	}
+	portNo, err := strconv.Atoi(port)
+	if err != nil || portNo < 0 || portNo > max_port {
+		return fmt.Errorf("port %s is invalid", port)
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+const (
+	validate_tag     = "validate"
+	skip_field_name  = "-"
+	rule_divider     = ","
+	param_divider    = "="
+	rule_required    = "required"
+	rule_omitempty   = "omitempty"
+	rule_omitnil     = "omitnil"
+	rule_dive        = "dive"
+	path_divider     = "."
+	index_path_begin = "["
+	index_path_end   = "]"
+)
+
+// ErrValidation is returned (wrapping Errors) when a value has violations
+var ErrValidation = errors.InvalidArgument("validation failed")
+
+// Rule checks the value of a field, the param is the text after = in the tag (e.g. 5 in min=5) |
+// the returned error is reported as the message of the violation
+type Rule func(value reflect.Value, param string) error
+
+// FieldError describes a single violation
+type FieldError struct {
+	Field   string // Field is the path of the field (e.g. Tls.CA or Hosts[0]) |
+	Rule    string // Rule is the name of the violated rule |
+	Message string // Message describes the problem
+}
+
+func (fe FieldError) Error() string {
+	return fe.Field + ": " + fe.Message
+}
+
+// Errors has all the violations found in a value
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fieldError := range e {
+		msgs = append(msgs, fieldError.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+var (
+	rulesLock sync.RWMutex
+	rules     = map[string]Rule{}
+)
+
+func init() {
+	for name, rule := range builtinRules {
+		rules[name] = rule
+	}
+}
+
+// registers a custom rule which can then be used in the validate tags |
+// the built in rules and the modifiers (required, omitempty, omitnil, dive) cannot be replaced
+func RegisterRule(name string, rule Rule) error {
+	if name == "" || rule == nil || strings.ContainsAny(name, rule_divider+param_divider) {
+		return errors.InvalidArgument("rule cannot have empty or invalid name or nil func")
+	}
+	if _, ok := builtinRules[name]; ok || isModifier(name) {
+		return errors.AlreadyExists("rule " + name + " is built in")
+	}
+	rulesLock.Lock()
+	defer rulesLock.Unlock()
+	rules[name] = rule
+	return nil
+}
+
+// checks the struct (or pointer to struct) against the validate tags of its fields |
+// all the violations are collected, the returned error is ErrValidation wrapping Errors |
+// nested structs are validated recursively (pointer cycles are followed once), dive applies the rules after it to the elements of slices and maps
+func Validate(v any) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ErrValidation.WithMessage("cannot validate a nil value")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return ErrValidation.WithMessage("only structs can be validated, got " + value.Kind().String())
+	}
+
+	vd := validator{visiting: map[visit]bool{}}
+	vd.validateStruct(value, "")
+	if len(vd.errs) > 0 {
+		return ErrValidation.Wrap(vd.errs)
+	}
+	return nil
+}
+
+// visit identifies a pointer being validated, the type is part of it since a struct and its first field share the address
+type visit struct {
+	pointer uintptr
+	typ     reflect.Type
+}
+
+// validator holds the state of a Validate call
+type validator struct {
+	errs     Errors
+	visiting map[visit]bool // visiting are the pointers on the current path, a pointer cycle is not followed twice
+}
+
+// returns the violations of the error returned by Validate, nil for the other errors
+func Violations(err error) Errors {
+	var errs Errors
+	if errors.As(err, &errs) {
+		return errs
+	}
+	return nil
+}
+
+// validates all the exported fields of the struct
+func (v *validator) validateStruct(structValue reflect.Value, path string) {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get(validate_tag)
+		if tag == skip_field_name {
+			continue
+		}
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + path_divider + field.Name
+		}
+		v.validateValue(structValue.Field(i), fieldPath, parseTag(tag))
+	}
+}
+
+type parsedRule struct {
+	name  string
+	param string
+}
+
+// splits the tag into its rules
+func parseTag(tag string) []parsedRule {
+	if tag == "" {
+		return nil
+	}
+	parsed := []parsedRule{}
+	for _, rule := range strings.Split(tag, rule_divider) {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), param_divider)
+		if name == "" {
+			continue
+		}
+		parsed = append(parsed, parsedRule{name: name, param: param})
+	}
+	return parsed
+}
+
+// validates the value against the rules and recurses into structs, slices and maps
+func (v *validator) validateValue(value reflect.Value, path string, fieldRules []parsedRule) {
+
+	// the rules after dive apply to the elements
+	var elemRules []parsedRule
+	dive := false
+	for i, rule := range fieldRules {
+		if rule.name == rule_dive {
+			elemRules = fieldRules[i+1:]
+			fieldRules = fieldRules[:i]
+			dive = true
+			break
+		}
+	}
+
+	// nil pointers are skipped with omitnil (or omitempty), the rules apply to the pointed value otherwise
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			if !hasRule(fieldRules, rule_omitnil) && !hasRule(fieldRules, rule_omitempty) && hasRule(fieldRules, rule_required) {
+				v.errs = append(v.errs, FieldError{Field: path, Rule: rule_required, Message: "is required"})
+			}
+			return
+		}
+
+		// stop at a pointer which is already being validated up the path
+		if value.Kind() == reflect.Pointer {
+			key := visit{pointer: value.Pointer(), typ: value.Type()}
+			if v.visiting[key] {
+				return
+			}
+			v.visiting[key] = true
+			defer delete(v.visiting, key)
+		}
+		value = value.Elem()
+	}
+	if hasRule(fieldRules, rule_omitempty) && isEmpty(value) {
+		return
+	}
+
+	// a missing required value is not checked further
+	if hasRule(fieldRules, rule_required) && isEmpty(value) {
+		v.errs = append(v.errs, FieldError{Field: path, Rule: rule_required, Message: "is required"})
+		return
+	}
+
+	// apply the rules
+	for _, rule := range fieldRules {
+		if isModifier(rule.name) {
+			continue
+		}
+		rulesLock.RLock()
+		ruleFunc, ok := rules[rule.name]
+		rulesLock.RUnlock()
+		if !ok {
+			v.errs = append(v.errs, FieldError{Field: path, Rule: rule.name, Message: "unknown rule " + rule.name})
+			continue
+		}
+		err := ruleFunc(value, rule.param)
+		if err != nil {
+			v.errs = append(v.errs, FieldError{Field: path, Rule: rule.name, Message: err.Error()})
+		}
+	}
+
+	// recurse into the nested values
+	switch value.Kind() {
+	case reflect.Struct:
+		if !isOpaque(value.Type()) {
+			v.validateStruct(value, path)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			elem := value.Index(i)
+			if dive || isStructLike(elem.Type()) {
+				v.validateValue(elem, path+index_path_begin+strconv.Itoa(i)+index_path_end, elemRules)
+			}
+		}
+	case reflect.Map:
+		if dive || isStructLike(value.Type().Elem()) {
+			iter := value.MapRange()
+			for iter.Next() {
+				v.validateValue(iter.Value(), path+index_path_begin+formatKey(iter.Key())+index_path_end, elemRules)
+			}
+		}
+	}
+}
+
+// reports whether the rules contain the rule
+func hasRule(fieldRules []parsedRule, name string) bool {
+	for _, rule := range fieldRules {
+		if rule.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// reports whether the name is one of the modifiers, which are handled by validateValue
+func isModifier(name string) bool {
+	return name == rule_required || name == rule_omitempty || name == rule_omitnil || name == rule_dive
+}
+
+// reports whether the value is empty, strings, slices and maps are empty when their length is zero
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len() == 0
+	case reflect.Invalid:
+		return true
+	}
+	return value.IsZero()
+}
+
+// structs without exported fields (e.g. time.Time) are not recursed into
+func isOpaque(structType reflect.Type) bool {
+	for i := 0; i < structType.NumField(); i++ {
+		if structType.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
+
+// reports whether the type is a struct (or pointer to struct) which is validated without dive
+func isStructLike(elemType reflect.Type) bool {
+	for elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	return elemType.Kind() == reflect.Struct && !isOpaque(elemType)
+}
+
+// formats the map key for the field path
+func formatKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	return fmt.Sprint(key.Interface())
+}
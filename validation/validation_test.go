@@ -0,0 +1,81 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gnanasuryateja/golib/errors"
+)
+
+type node struct {
+	Name string `validate:"required"`
+	Next *node
+}
+
+type config struct {
+	Host     string            `validate:"required"`
+	Level    string            `validate:"omitempty,oneofci=DEBUG INFO"`
+	Port     int               `validate:"min=1,max=65535"`
+	Timeout  time.Duration     `validate:"min=0,max=30s"`
+	Hosts    []string          `validate:"dive,hostport"`
+	CA       *string           `validate:"omitnil,file-exists"`
+	CRT      *string           `validate:"omitnil,file_exists"`
+	Labels   map[string]string `validate:"dive,required"`
+	Internal string            `validate:"-"`
+}
+
+func TestValidate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(file, []byte("ca"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+	valid := config{Host: "localhost", Level: "info", Port: 6379, Timeout: time.Second, Hosts: []string{"a:1"}, CA: &file, CRT: &file}
+
+	tests := []struct {
+		name   string
+		modify func(c *config)
+		fields []string
+	}{
+		{"valid", func(c *config) {}, nil},
+		{"required", func(c *config) { c.Host = "" }, []string{"Host"}},
+		{"oneofci", func(c *config) { c.Level = "trace" }, []string{"Level"}},
+		{"min and max", func(c *config) { c.Port = 0; c.Timeout = time.Minute }, []string{"Port", "Timeout"}},
+		{"dive", func(c *config) { c.Hosts = []string{"a:1", "b"} }, []string{"Hosts[1]"}},
+		{"file-exists", func(c *config) { c.CA = &missing }, []string{"CA"}},
+		{"file_exists alias", func(c *config) { c.CRT = &missing }, []string{"CRT"}},
+		{"dive map", func(c *config) { c.Labels = map[string]string{"team": ""} }, []string{"Labels[team]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			err := Validate(c)
+			violations := Violations(err)
+			if len(violations) != len(tt.fields) {
+				t.Fatalf("Validate() = %v, want violations of %v", err, tt.fields)
+			}
+			if err != nil && !errors.Is(err, ErrValidation) {
+				t.Errorf("Validate() = %v, want ErrValidation", err)
+			}
+			for i, field := range tt.fields {
+				if violations[i].Field != field {
+					t.Errorf("violation %d field = %s, want %s", i, violations[i].Field, field)
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePointerCycle(t *testing.T) {
+	first := &node{Name: "first"}
+	second := &node{Next: first}
+	first.Next = second
+
+	violations := Violations(Validate(first))
+	if len(violations) != 1 || violations[0].Field != "Next.Name" {
+		t.Errorf("Validate() violations = %v, want Next.Name only", violations)
+	}
+}